@@ -5,13 +5,19 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/NailUsmanov/linkchecker11_11_2025/internal/app"
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/config"
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/service"
 	"github.com/NailUsmanov/linkchecker11_11_2025/internal/storage"
 	"go.uber.org/zap"
 )
 
 func main() {
+	// Читаю настройки
+	cfg := config.NewConfig()
+
 	// Запускаю логирование
 	logger, err := zap.NewDevelopment()
 	if err != nil {
@@ -25,17 +31,57 @@ func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 	// хранилище для ссылок
-	store, err := storage.NewFileStorage("data.json")
+	store, err := storage.NewFileStorage(cfg.DataPath)
 	if err != nil {
 		sugar.Fatalf("create file storage failed: %v", err)
 	}
 
+	// лимиты на отдельные домены
+	hostLimits, err := service.ParseHostLimits(cfg.HostLimits)
+	if err != nil {
+		sugar.Fatalf("parse host limits failed: %v", err)
+	}
+
+	// внутренние адреса, которые всё же можно проверять
+	allow, err := service.ParseAllowlist(cfg.Allow)
+	if err != nil {
+		sugar.Fatalf("parse allowlist failed: %v", err)
+	}
+
+	// пул проверки ссылок
+	checker := service.NewLinkChecker(service.CheckerConfig{
+		Workers:        cfg.Workers,
+		Timeout:        cfg.BatchTimeout,
+		RequestTimeout: cfg.RequestTimeout,
+		Retry: service.RetryPolicy{
+			MaxAttempts: cfg.RetryAttempts,
+			BaseDelay:   cfg.RetryBaseDelay,
+			MaxDelay:    cfg.RetryMaxDelay,
+		},
+		HostLimits: service.HostLimits{
+			Default: service.HostLimit{
+				MaxConcurrent: cfg.HostConcurrency,
+				MinDelay:      cfg.HostDelay,
+			},
+			Domains: hostLimits,
+		},
+		Allow:            allow,
+		CertExpiryWindow: time.Duration(cfg.CertWarnDays) * 24 * time.Hour,
+		SlowThreshold:    cfg.SlowThreshold,
+		CacheTTL:         cfg.CacheTTL,
+		UserAgent:        cfg.UserAgent,
+		MaxCrawlDelay:    cfg.MaxCrawlDelay,
+	})
+
+	// очередь фоновых проверок
+	jobs := service.NewJobQueue(store, checker, cfg.JobWorkers, cfg.QueueSize)
+
 	// создаем арр
-	applictaion := app.NewApp(store, sugar)
+	applictaion := app.NewApp(store, jobs, sugar)
 
 	// Логирую запуск сервера и вызывваю Run
-	sugar.Infow("starting HTTP server", "addr", ":8080")
-	if err := applictaion.Run(ctx, ":8080"); err != nil {
+	sugar.Infow("starting HTTP server", "addr", cfg.Addr)
+	if err := applictaion.Run(ctx, cfg.Addr); err != nil {
 		sugar.Fatalln(err)
 	}
 	sugar.Infow("server stop")
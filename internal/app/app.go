@@ -6,25 +6,31 @@ import (
 	"time"
 
 	"github.com/NailUsmanov/linkchecker11_11_2025/internal/handler"
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/service"
 	"github.com/NailUsmanov/linkchecker11_11_2025/internal/storage"
 	"github.com/go-chi/chi"
 	"go.uber.org/zap"
 )
 
-// App - состоит из маршуртизатора chi, храншлища, логгера.
+// ShutdownTimeout - сколько ждём завершения текущих запросов и задач при остановке.
+const ShutdownTimeout = 5 * time.Second
+
+// App - состоит из маршуртизатора chi, храншлища, очереди задач проверки, логгера.
 type App struct {
 	router  *chi.Mux
 	storage storage.Storage
+	jobs    *service.JobQueue
 	sugar   *zap.SugaredLogger
 }
 
 // NewApp - создадим новую стркутуру Арр.
 // В ней регистрируем маршруты.
-func NewApp(s storage.Storage, sugar *zap.SugaredLogger) *App {
+func NewApp(s storage.Storage, jobs *service.JobQueue, sugar *zap.SugaredLogger) *App {
 	r := chi.NewRouter()
 	app := &App{
 		router:  r,
 		storage: s,
+		jobs:    jobs,
 		sugar:   sugar,
 	}
 	app.setupRoutes()
@@ -32,27 +38,62 @@ func NewApp(s storage.Storage, sugar *zap.SugaredLogger) *App {
 }
 
 func (a *App) setupRoutes() {
-	a.router.Post("/links", handler.NewCreateLinks(a.storage, a.sugar))
+	a.router.Post("/links", handler.NewCreateLinks(a.jobs, a.sugar))
 	a.router.Get("/links_num", handler.NewGetLinks(a.storage, a.sugar))
+	a.router.Get("/jobs/{id}", handler.NewGetJob(a.storage, a.jobs, a.sugar))
+
+	// получение отчётов без тела запроса, ссылки можно сохранять в закладки
+	a.router.Get("/reports", handler.NewGetReport(a.storage, a.sugar))
+	a.router.Get("/reports/{num}", handler.NewGetReport(a.storage, a.sugar))
+	a.router.Get("/links/{num}/report", handler.NewGetReport(a.storage, a.sugar))
 }
 
 // Run будет запускать HTTP-сервер на указаноом адресе
+// При остановке сервер перестаёт принимать запросы и даёт задачам ShutdownTimeout на завершение.
+// Не успевшие задачи остаются в хранилище и доделываются при следующем запуске.
 func (a *App) Run(ctx context.Context, addr string) error {
 	srv := http.Server{
 		Addr:    addr,
 		Handler: a.router,
 	}
 
+	// у воркеров свой контекст: они должны пережить начало остановки сервера
+	jobsCtx, cancelJobs := context.WithCancel(context.Background())
+	defer cancelJobs()
+
+	restored, err := a.jobs.Restore(jobsCtx)
+	if err != nil {
+		return err
+	}
+	if restored > 0 {
+		a.sugar.Infow("restored unfinished jobs", "count", restored)
+	}
+
+	jobsDone := make(chan struct{})
+	go func() {
+		defer close(jobsDone)
+		a.jobs.Run(jobsCtx)
+	}()
+
+	stopped := make(chan struct{})
 	go func() {
+		defer close(stopped)
 		<-ctx.Done()
 		a.sugar.Infof("Shutdown the server")
-		shutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		shutCtx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
 		defer cancel()
+
+		// ждём текущие запросы, а вместе с ними и их задачи
 		_ = srv.Shutdown(shutCtx)
+
+		// оставшиеся задачи прерываем, они сохранены и продолжатся после перезапуска
+		cancelJobs()
+		<-jobsDone
 	}()
 
 	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		return err
 	}
+	<-stopped
 	return nil
 }
@@ -1,11 +1,13 @@
 package app
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/service"
 	"github.com/NailUsmanov/linkchecker11_11_2025/internal/storage"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -17,7 +19,13 @@ func TestAppRoutes(t *testing.T) {
 	logger := zap.NewNop()
 	defer logger.Sync()
 
-	app := NewApp(mockStore, logger.Sugar())
+	checker := service.NewLinkChecker(service.CheckerConfig{})
+	jobs := service.NewJobQueue(mockStore, checker, 1, 10)
+	app := NewApp(mockStore, jobs, logger.Sugar())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go jobs.Run(ctx)
 
 	t.Run("Create link and Get", func(t *testing.T) {
 		reqBody := `{"links":["google.com"]}`
@@ -50,6 +58,12 @@ func TestAppRoutes(t *testing.T) {
 
 		assert.Equal(t, http.StatusOK, resGet.StatusCode, "Expected 200 status code")
 		assert.Equal(t, "application/pdf", resGet.Header.Get("Content-Type"))
+
+		// тот же отчёт по пути, без тела
+		recReport := httptest.NewRecorder()
+		app.router.ServeHTTP(recReport, httptest.NewRequest(http.MethodGet, "/reports/1", nil))
+		assert.Equal(t, http.StatusOK, recReport.Code)
+		assert.Equal(t, "application/pdf", recReport.Header().Get("Content-Type"))
 	})
 
 }
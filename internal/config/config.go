@@ -0,0 +1,76 @@
+package config
+
+import (
+	"flag"
+	"time"
+)
+
+// Config - настройки сервиса, задаются флагами командной строки.
+type Config struct {
+	// Addr - адрес, на котором слушает HTTP-сервер.
+	Addr string
+	// DataPath - путь к файлу хранилища.
+	DataPath string
+	// Workers - сколько ссылок проверяется одновременно в рамках одного запроса.
+	Workers int
+	// BatchTimeout - общий дедлайн на проверку всех ссылок одного запроса.
+	BatchTimeout time.Duration
+	// JobWorkers - сколько задач проверки (синхронных и фоновых) обрабатывается одновременно.
+	JobWorkers int
+	// QueueSize - ёмкость очереди фоновых задач.
+	QueueSize int
+	// RequestTimeout - таймаут одного HTTP-запроса к проверяемой ссылке.
+	RequestTimeout time.Duration
+	// RetryAttempts - всего попыток на ссылку при временных ошибках, 1 - без повторов.
+	RetryAttempts int
+	// RetryBaseDelay - пауза перед первым повтором, дальше удваивается.
+	RetryBaseDelay time.Duration
+	// RetryMaxDelay - потолок паузы между повторами и предел для Retry-After.
+	RetryMaxDelay time.Duration
+	// HostConcurrency - сколько запросов к одному хосту идёт одновременно, 0 - без ограничения.
+	HostConcurrency int
+	// HostDelay - минимальная пауза между запросами к одному хосту.
+	HostDelay time.Duration
+	// HostLimits - отдельные лимиты для доменов в виде "example.com=2/500ms,intra.local=1".
+	HostLimits string
+	// Allow - внутренние сети и хосты, которые разрешено проверять, например "10.1.0.0/16,intra.local".
+	Allow string
+	// CertWarnDays - за сколько дней до окончания срока сертификат считается истекающим.
+	CertWarnDays int
+	// SlowThreshold - ответ медленнее этого порога помечается как degraded, 0 - не помечать.
+	SlowThreshold time.Duration
+	// CacheTTL - сколько хранить результаты проверок, 0 - не кэшировать.
+	CacheTTL time.Duration
+	// UserAgent - с каким User-Agent ходим по ссылкам, по нему же ищутся правила в robots.txt.
+	UserAgent string
+	// MaxCrawlDelay - верхний предел паузы Crawl-delay из robots.txt.
+	MaxCrawlDelay time.Duration
+}
+
+// NewConfig - разбирает флаги и возвращает конфигурацию.
+func NewConfig() *Config {
+	cfg := &Config{}
+
+	flag.StringVar(&cfg.Addr, "a", ":8080", "HTTP server address")
+	flag.StringVar(&cfg.DataPath, "f", "data.json", "path to storage file")
+	flag.IntVar(&cfg.Workers, "workers", 10, "number of concurrent link checks per request")
+	flag.DurationVar(&cfg.BatchTimeout, "batch-timeout", 30*time.Second, "overall deadline for checking one request")
+	flag.IntVar(&cfg.JobWorkers, "job-workers", 4, "number of check jobs processed concurrently")
+	flag.IntVar(&cfg.QueueSize, "queue-size", 100, "capacity of the background job queue")
+	flag.DurationVar(&cfg.RequestTimeout, "request-timeout", 5*time.Second, "timeout of a single HTTP request")
+	flag.IntVar(&cfg.RetryAttempts, "retries", 3, "attempts per link on transient errors (1 disables retries)")
+	flag.DurationVar(&cfg.RetryBaseDelay, "retry-base-delay", 200*time.Millisecond, "initial backoff between retries")
+	flag.DurationVar(&cfg.RetryMaxDelay, "retry-max-delay", 5*time.Second, "maximum backoff and Retry-After honored")
+	flag.IntVar(&cfg.HostConcurrency, "host-concurrency", 4, "max concurrent requests to one host (0 - unlimited)")
+	flag.DurationVar(&cfg.HostDelay, "host-delay", 50*time.Millisecond, "minimum delay between requests to one host")
+	flag.StringVar(&cfg.HostLimits, "host-limits", "", "per-domain limits, e.g. example.com=2/500ms,intra.local=1")
+	flag.StringVar(&cfg.Allow, "allow", "", "private networks and hosts allowed for checks, e.g. 10.1.0.0/16,intra.local")
+	flag.IntVar(&cfg.CertWarnDays, "cert-warn-days", 14, "days before certificate expiry to flag it as expiring")
+	flag.DurationVar(&cfg.SlowThreshold, "slow-threshold", 2*time.Second, "responses slower than this are marked degraded (0 disables)")
+	flag.DurationVar(&cfg.CacheTTL, "cache-ttl", time.Minute, "how long check results are reused (0 disables the cache)")
+	flag.StringVar(&cfg.UserAgent, "user-agent", "linkchecker/1.0", "User-Agent for checks and robots.txt rules")
+	flag.DurationVar(&cfg.MaxCrawlDelay, "max-crawl-delay", 10*time.Second, "upper bound for Crawl-delay from robots.txt")
+	flag.Parse()
+
+	return cfg
+}
@@ -2,26 +2,24 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
-	"sync/atomic"
 
 	"github.com/NailUsmanov/linkchecker11_11_2025/internal/models"
 	"github.com/NailUsmanov/linkchecker11_11_2025/internal/service"
 	"github.com/NailUsmanov/linkchecker11_11_2025/internal/storage"
+	"github.com/go-chi/chi"
 	"go.uber.org/zap"
 )
 
-// reqNum - нужен для инкрементного увеличения счетчика запросов.
-var reqNum atomic.Uint64
-
-// NextReqID - увеличивает счетчик запросов инкрементно.
-func NextReqNum() uint64 {
-	return reqNum.Add(1)
-}
-
 // NewCreateLinks - проверяет и сохраняет переданные в запросе ссылки.
-func NewCreateLinks(s storage.Storage, sugar *zap.SugaredLogger) http.HandlerFunc {
+// Кроме JSON принимает текст, Markdown, HTML и загрузку файлов (см. decodeLinksRequest).
+// Проверка всегда идёт через очередь задач, поэтому не зависит от контекста запроса
+// и переживает остановку сервиса. При "async": true клиент сразу получает 202 с номером задачи.
+func NewCreateLinks(jobs *service.JobQueue, sugar *zap.SugaredLogger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Проверим метод
 		if r.Method != http.MethodPost {
@@ -29,58 +27,100 @@ func NewCreateLinks(s storage.Storage, sugar *zap.SugaredLogger) http.HandlerFun
 			return
 		}
 
-		// проверим, что запрос отправлен в виде JSON формата
-		ct := r.Header.Get("Content-Type")
-		if !strings.HasPrefix(ct, "application/json") {
-			http.Error(w, "Invalid content type", http.StatusBadRequest)
-			return
-		}
-
-		// берем данные из запроса
+		// берем данные из запроса: JSON или документы, из которых достаём ссылки
 		defer r.Body.Close()
 
-		var req models.RequestSentLinks
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		req, err := decodeLinksRequest(w, r)
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.As(err, &tooLarge):
+			http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+			return
+		case errors.Is(err, errInvalidJSON):
 			sugar.Errorf("cannot decode requset JSON body: %v", err)
 			http.Error(w, "Invalid JSON format", http.StatusBadRequest)
 			return
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
 		}
 
-		if len(req.Links) == 0 {
+		if len(req.Links) == 0 && len(req.Sitemaps) == 0 {
 			http.Error(w, "Empty request body", http.StatusBadRequest)
 			return
 		}
 
-		// Получаем номер запроса
-		numReq := NextReqNum()
+		if !req.RedirectPolicy.Valid() {
+			http.Error(w, "Invalid redirect policy", http.StatusBadRequest)
+			return
+		}
 
-		// Возвращаем ответ
-		resp := models.ResponseSentLinks{
-			Num:   int(numReq),
-			Links: make(map[string]string, len(req.Links)),
+		if err := req.Crawl.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
 		}
 
-		for _, v := range req.Links {
-			stat, err := service.CheckLink(r.Context(), v)
-			statusStr := "not available"
+		// ожидания проверяем сразу, чтобы не узнать о кривой регулярке из отчёта
+		if err := validateExpectations(req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 
-			if err != nil {
-				// Линку не смогли проверить — считаем недоступной, идем дальше
-				sugar.Warnf("checklink failed: %v", err)
-			} else if stat {
-				statusStr = "avaivable"
-			}
-			resp.Links[v] = statusStr
+		// Карты сайта скачивает задача, здесь только проверяем адреса
+		if err := service.ValidateSitemaps(req.Sitemaps); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
 		}
 
-		// Сохраняем ссылки
-		err := s.Save(r.Context(), resp)
+		// Ставим ссылки в очередь, номер задачи совпадает с номером запроса
+		job, err := jobs.Enqueue(r.Context(), req.Links, req.Sitemaps, req.CheckOptions)
+		if errors.Is(err, service.ErrQueueFull) {
+			sugar.Warnf("enqueue links failed: %v", err)
+			http.Error(w, "job queue is full", http.StatusServiceUnavailable)
+			return
+		}
 		if err != nil {
-			sugar.Errorf("save links failed: %v", err)
-			http.Error(w, "save links failed", http.StatusInternalServerError)
+			sugar.Errorf("enqueue links failed: %v", err)
+			http.Error(w, "enqueue links failed", http.StatusInternalServerError)
 			return
 		}
 
+		// Асинхронный режим: отдаём номер задачи, проверка идёт в фоне
+		if req.Async {
+			writeJobAccepted(w, job, sugar)
+			return
+		}
+
+		// Синхронный режим: ждём результат задачи
+		resp, err := jobs.Wait(r.Context(), job.Num)
+		switch {
+		case errors.Is(err, service.ErrQueueStopped):
+			// Сервис останавливается — задача сохранена и будет доделана после перезапуска
+			sugar.Infow("service is stopping, job postponed", "links_num", job.Num)
+			writeJobAccepted(w, job, sugar)
+			return
+		case errors.Is(err, service.ErrJobFailed):
+			// например, карта сайта недоступна: причина та же, что в GET /jobs/{id}
+			sugar.Warnf("job %d failed: %v", job.Num, err)
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		case err != nil && r.Context().Err() != nil:
+			// Клиент ушёл, задача доделается в фоне
+			sugar.Infow("client gone, job continues in background", "links_num", job.Num)
+			return
+		case err != nil:
+			sugar.Errorf("check links failed: %v", err)
+			http.Error(w, "check links failed", http.StatusInternalServerError)
+			return
+		}
+
+		for _, res := range resp.Links {
+			if res.Error != "" {
+				// Линку не смогли проверить — она уже помечена недоступной
+				sugar.Warnf("checklink %s failed: %s", res.URL, res.Error)
+			}
+		}
+
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusCreated)
 
@@ -90,7 +130,32 @@ func NewCreateLinks(s storage.Storage, sugar *zap.SugaredLogger) http.HandlerFun
 	}
 }
 
+// writeJobAccepted - отвечает 202 с состоянием задачи и ссылкой на неё.
+func writeJobAccepted(w http.ResponseWriter, job models.Job, sugar *zap.SugaredLogger) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", fmt.Sprintf("/jobs/%d", job.Num))
+	w.WriteHeader(http.StatusAccepted)
+
+	if err := json.NewEncoder(w).Encode(job.Status()); err != nil {
+		sugar.Errorf("error encoding response: %v", err)
+	}
+}
+
+// validateExpectations - общие ожидания запроса и ожидания каждой ссылки.
+func validateExpectations(req models.RequestSentLinks) error {
+	if err := req.Expect.Validate(); err != nil {
+		return err
+	}
+	for _, link := range req.Links {
+		if err := link.Expect.Validate(); err != nil {
+			return fmt.Errorf("%s: %w", link.URL, err)
+		}
+	}
+	return nil
+}
+
 // NewGetLinks - выдает пользователю PDF файл по конкретному номеру запроса с уже проверенными ссылками.
+// Другой формат - только через ?format=: Accept здесь не смотрим, чтобы старые клиенты по-прежнему получали PDF.
 func NewGetLinks(s storage.Storage, sugar *zap.SugaredLogger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Проверяю метод
@@ -121,40 +186,51 @@ func NewGetLinks(s storage.Storage, sugar *zap.SugaredLogger) http.HandlerFunc {
 			return
 		}
 
-		// Достаем ссылки из базы данных
-		data, err := s.Get(r.Context(), reqNums.LinksList)
-		if err != nil {
-			sugar.Errorf("get links failed: %v", err)
-			http.Error(w, "get links failed", http.StatusInternalServerError)
+		renderer, ok := pickLegacyRenderer(r)
+		if !ok {
+			http.Error(w, "unsupported report format", http.StatusBadRequest)
 			return
 		}
+		writeReport(w, r, s, reqNums.LinksList, renderer, sugar)
+	}
+}
 
-		// Проверю, что все номера нашлись
-		if len(data) != len(reqNums.LinksList) {
-			missing := make([]int, 0)
-			for _, n := range reqNums.LinksList {
-				if _, ok := data[n]; !ok {
-					missing = append(missing, n)
-				}
-			}
-
-			sugar.Warnw("some request numbers not found", "missing", missing)
-			http.Error(w, "some request numbers not found", http.StatusNotFound)
+// NewGetJob - выдает состояние фоновой задачи по номеру из пути /jobs/{id}.
+func NewGetJob(s storage.Storage, jobs *service.JobQueue, sugar *zap.SugaredLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		num, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil || num <= 0 {
+			http.Error(w, "Invalid job number", http.StatusBadRequest)
 			return
 		}
 
-		// Собираем PDF
-		buf, err := service.CreatePDF(data)
-		if err != nil {
-			sugar.Errorf("create pdf failed: %v", err)
-			http.Error(w, "create pdf failed", http.StatusInternalServerError)
-			return
+		job, ok := jobs.Get(num)
+		if !ok {
+			// Задачи нет в очереди — значит она уже завершена и лежит в хранилище
+			data, err := s.Get(r.Context(), []int{num})
+			if err != nil {
+				sugar.Errorf("get links failed: %v", err)
+				http.Error(w, "get links failed", http.StatusInternalServerError)
+				return
+			}
+			resp, found := data[num]
+			if !found {
+				http.Error(w, "job not found", http.StatusNotFound)
+				return
+			}
+			job = models.Job{
+				Num:     num,
+				State:   models.JobDone,
+				Total:   len(resp.Links),
+				Checked: len(resp.Links),
+			}
 		}
 
-		// Возвращаем ответ
-		w.Header().Set("Content-Type", "application/pdf")
-		w.Header().Set("Content-Disposition", "attachment; filename=report.pdf")
+		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write(buf)
+
+		if err := json.NewEncoder(w).Encode(job.Status()); err != nil {
+			sugar.Errorf("error encoding response: %v", err)
+		}
 	}
 }
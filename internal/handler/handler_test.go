@@ -3,27 +3,39 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/NailUsmanov/linkchecker11_11_2025/internal/models"
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/service"
+	"github.com/go-chi/chi"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
 
 type MockStorage struct {
-	Data map[int]models.ResponseSentLinks
+	mu       sync.Mutex
+	Data     map[int]models.ResponseSentLinks
+	JobsData map[int]models.Job
+	LastID   int
 }
 
 func (m *MockStorage) Save(ctx context.Context, links models.ResponseSentLinks) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.Data[links.Num] = links
 	return nil
 }
 
 func (m *MockStorage) Get(ctx context.Context, num []int) (map[int]models.ResponseSentLinks, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	result := make(map[int]models.ResponseSentLinks)
 	for _, n := range num {
 		if v, ok := m.Data[n]; ok {
@@ -33,6 +45,40 @@ func (m *MockStorage) Get(ctx context.Context, num []int) (map[int]models.Respon
 	return result, nil
 }
 
+func (m *MockStorage) NextID(ctx context.Context) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.LastID++
+	return m.LastID, nil
+}
+
+func (m *MockStorage) SaveJob(ctx context.Context, job models.Job) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.JobsData == nil {
+		m.JobsData = make(map[int]models.Job)
+	}
+	m.JobsData[job.Num] = job
+	return nil
+}
+
+func (m *MockStorage) DeleteJob(ctx context.Context, num int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.JobsData, num)
+	return nil
+}
+
+func (m *MockStorage) Jobs(ctx context.Context) ([]models.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	res := make([]models.Job, 0, len(m.JobsData))
+	for _, j := range m.JobsData {
+		res = append(res, j)
+	}
+	return res, nil
+}
+
 func TestNewCreateLinks(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -54,20 +100,48 @@ func TestNewCreateLinks(t *testing.T) {
 			requestBody: `{"links":[]}`,
 			wantStatus:  http.StatusBadRequest,
 		},
+		{
+			name:        "unknown redirect policy",
+			requestBody: `{"links":["ya.ru"],"redirect_policy":"sometimes"}`,
+			wantStatus:  http.StatusBadRequest,
+		},
+		{
+			name:        "invalid sitemap url",
+			requestBody: `{"sitemaps":["ftp://example.com/sitemap.xml"]}`,
+			wantStatus:  http.StatusBadRequest,
+		},
+		{
+			name:        "unknown crawl scope",
+			requestBody: `{"links":["ya.ru"],"crawl":{"depth":2,"scope":"everywhere"}}`,
+			wantStatus:  http.StatusBadRequest,
+		},
+		{
+			name:        "invalid expect regex",
+			requestBody: `{"links":["ya.ru"],"expect":{"body_matches":"("}}`,
+			wantStatus:  http.StatusBadRequest,
+		},
+		{
+			name:        "invalid link expect",
+			requestBody: `{"links":["ya.ru",{"url":"google.com","expect":{"status":[42]}}]}`,
+			wantStatus:  http.StatusBadRequest,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 
-			//Сброс атомик счетчика для будущих тестов
-			reqNum.Store(0)
-
 			storage := &MockStorage{Data: make(map[int]models.ResponseSentLinks)}
 			logger := zap.NewNop()
 			defer logger.Sync()
 			sugar := logger.Sugar()
 
-			handler := NewCreateLinks(storage, sugar)
+			checker := service.NewLinkChecker(service.CheckerConfig{})
+			jobs := service.NewJobQueue(storage, checker, 1, 10)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go jobs.Run(ctx)
+
+			handler := NewCreateLinks(jobs, sugar)
 
 			req := httptest.NewRequest(http.MethodPost, "/links", strings.NewReader(tt.requestBody))
 			req.Header.Set("Content-Type", "application/json")
@@ -100,13 +174,45 @@ func TestNewCreateLinks(t *testing.T) {
 func TestNewGetLinks(t *testing.T) {
 	tests := []struct {
 		name        string
+		target      string
+		accept      string
 		requestBody string
 		wantStatus  int
+		wantType    string
 	}{
 		{
 			name:        "correct",
 			requestBody: `{"links_list":[1]}`,
 			wantStatus:  http.StatusOK,
+			wantType:    "application/pdf",
+		},
+		{
+			name:        "accept is ignored",
+			accept:      "application/json",
+			requestBody: `{"links_list":[1]}`,
+			wantStatus:  http.StatusOK,
+			wantType:    "application/pdf",
+		},
+		{
+			name:        "unmatched accept",
+			accept:      "application/octet-stream",
+			requestBody: `{"links_list":[1]}`,
+			wantStatus:  http.StatusOK,
+			wantType:    "application/pdf",
+		},
+		{
+			name:        "explicit format",
+			target:      "/links_num?format=csv",
+			accept:      "application/json",
+			requestBody: `{"links_list":[1]}`,
+			wantStatus:  http.StatusOK,
+			wantType:    "text/csv",
+		},
+		{
+			name:        "unknown format",
+			target:      "/links_num?format=doc",
+			requestBody: `{"links_list":[1]}`,
+			wantStatus:  http.StatusBadRequest,
 		},
 		{
 			name:        "invalid JSON",
@@ -129,10 +235,10 @@ func TestNewGetLinks(t *testing.T) {
 
 			// Тестовые данные
 			links := models.ResponseSentLinks{
-				Links: map[string]string{
-					"google.com": "available",
-					"ya.ru":      "available",
-					"test.com":   "not available"},
+				Links: []models.CheckResult{
+					{URL: "google.com", Status: models.StatusAvailable, StatusCode: 200},
+					{URL: "ya.ru", Status: models.StatusAvailable, StatusCode: 200},
+					{URL: "test.com", Status: models.StatusClientError, StatusCode: 404}},
 				Num: 1,
 			}
 			ctx := context.Background()
@@ -141,8 +247,15 @@ func TestNewGetLinks(t *testing.T) {
 
 			handler := NewGetLinks(storage, sugar)
 
-			req := httptest.NewRequest(http.MethodGet, "/links_num", strings.NewReader(tt.requestBody))
+			target := tt.target
+			if target == "" {
+				target = "/links_num"
+			}
+			req := httptest.NewRequest(http.MethodGet, target, strings.NewReader(tt.requestBody))
 			req.Header.Set("Content-Type", "application/json")
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
 
 			w := httptest.NewRecorder()
 
@@ -154,7 +267,7 @@ func TestNewGetLinks(t *testing.T) {
 			assert.Equal(t, tt.wantStatus, res.StatusCode)
 
 			if tt.wantStatus == http.StatusOK {
-
+				assert.True(t, strings.HasPrefix(res.Header.Get("Content-Type"), tt.wantType), res.Header.Get("Content-Type"))
 			}
 		})
 	}
@@ -177,3 +290,123 @@ func TestNewGetLinks(t *testing.T) {
 		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
 	})
 }
+
+func TestNewCreateLinks_Async(t *testing.T) {
+	storage := &MockStorage{Data: make(map[int]models.ResponseSentLinks)}
+	sugar := zap.NewNop().Sugar()
+
+	checker := service.NewLinkChecker(service.CheckerConfig{})
+	// воркеры очереди не запущены, задача остаётся в состоянии queued
+	jobs := service.NewJobQueue(storage, checker, 1, 10)
+	handler := NewCreateLinks(jobs, sugar)
+
+	req := httptest.NewRequest(http.MethodPost, "/links", strings.NewReader(`{"links":["ya.ru","google.com"],"async":true}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handler(w, req)
+
+	res := w.Result()
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusAccepted, res.StatusCode)
+	assert.Equal(t, "/jobs/1", res.Header.Get("Location"))
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	// в ответе только состояние и прогресс, без списка ссылок
+	assert.NotContains(t, string(body), `"links"`)
+	assert.NotContains(t, string(body), `"options"`)
+
+	var job models.JobStatus
+	require.NoError(t, json.Unmarshal(body, &job))
+	assert.Equal(t, 1, job.Num)
+	assert.Equal(t, models.JobQueued, job.State)
+	assert.Equal(t, 2, job.Total)
+	assert.Empty(t, storage.Data)
+	// задача сохранена и переживёт перезапуск
+	assert.Contains(t, storage.JobsData, 1)
+}
+
+func TestNewCreateLinks_StoppedQueue(t *testing.T) {
+	storage := &MockStorage{Data: make(map[int]models.ResponseSentLinks)}
+	sugar := zap.NewNop().Sugar()
+
+	jobs := service.NewJobQueue(storage, service.NewLinkChecker(service.CheckerConfig{}), 1, 10)
+	// очередь сразу останавливается, как при выключении сервиса
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	jobs.Run(ctx)
+
+	handler := NewCreateLinks(jobs, sugar)
+
+	req := httptest.NewRequest(http.MethodPost, "/links", strings.NewReader(`{"links":["ya.ru"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handler(w, req)
+
+	res := w.Result()
+	defer res.Body.Close()
+
+	assert.Equal(t, http.StatusAccepted, res.StatusCode)
+	assert.Equal(t, "/jobs/1", res.Header.Get("Location"))
+	assert.Contains(t, storage.JobsData, 1)
+}
+
+func TestNewGetJob(t *testing.T) {
+	storage := &MockStorage{Data: make(map[int]models.ResponseSentLinks)}
+	sugar := zap.NewNop().Sugar()
+
+	checker := service.NewLinkChecker(service.CheckerConfig{})
+	jobs := service.NewJobQueue(storage, checker, 1, 10)
+
+	ctx := context.Background()
+	queued, err := jobs.Enqueue(ctx, models.LinkSpecs("ya.ru"), nil, models.CheckOptions{})
+	require.NoError(t, err)
+
+	require.NoError(t, storage.Save(ctx, models.ResponseSentLinks{
+		Num:   100,
+		Links: []models.CheckResult{{URL: "google.com", Status: models.StatusAvailable}},
+	}))
+
+	r := chi.NewRouter()
+	r.Get("/jobs/{id}", NewGetJob(storage, jobs, sugar))
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		wantState  models.JobState
+	}{
+		{name: "queued", path: fmt.Sprintf("/jobs/%d", queued.Num), wantStatus: http.StatusOK, wantState: models.JobQueued},
+		{name: "done", path: "/jobs/100", wantStatus: http.StatusOK, wantState: models.JobDone},
+		{name: "not found", path: "/jobs/99", wantStatus: http.StatusNotFound},
+		{name: "bad number", path: "/jobs/abc", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			res := w.Result()
+			defer res.Body.Close()
+
+			require.Equal(t, tt.wantStatus, res.StatusCode)
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			body, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			assert.NotContains(t, string(body), `"links"`)
+
+			var job models.JobStatus
+			require.NoError(t, json.Unmarshal(body, &job))
+			assert.Equal(t, tt.wantState, job.State)
+		})
+	}
+}
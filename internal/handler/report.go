@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/service"
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/storage"
+	"github.com/go-chi/chi"
+	"go.uber.org/zap"
+)
+
+var (
+	errEmptyIDs   = errors.New("no request numbers given")
+	errInvalidNum = errors.New("invalid request number")
+)
+
+// NewGetReport - выдает отчёт по номерам из пути или строки запроса, без тела запроса.
+// Поддерживает /reports/{num}, /links/{num}/report и /reports?ids=1,2,3.
+// Формат выбирается параметром ?format= или заголовком Accept, по умолчанию PDF.
+// Ссылки идут в порядке отправки, ?order=sorted - по алфавиту.
+func NewGetReport(s storage.Storage, sugar *zap.SugaredLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		nums, err := reportNums(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		renderer, code, ok := pickRenderer(r)
+		if !ok {
+			http.Error(w, "unsupported report format", code)
+			return
+		}
+		writeReport(w, r, s, nums, renderer, sugar)
+	}
+}
+
+// reportNums - достаёт номера запросов из {num} в пути или из параметра ids.
+func reportNums(r *http.Request) ([]int, error) {
+	if p := chi.URLParam(r, "num"); p != "" {
+		n, err := parseNum(p)
+		if err != nil {
+			return nil, err
+		}
+		return []int{n}, nil
+	}
+
+	raw := r.URL.Query().Get("ids")
+	if raw == "" {
+		return nil, errEmptyIDs
+	}
+
+	nums := make([]int, 0)
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		n, err := parseNum(part)
+		if err != nil {
+			return nil, err
+		}
+		nums = append(nums, n)
+	}
+	if len(nums) == 0 {
+		return nil, errEmptyIDs
+	}
+	return nums, nil
+}
+
+// parseNum - номер запроса должен быть положительным числом.
+func parseNum(s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n <= 0 {
+		return 0, errInvalidNum
+	}
+	return n, nil
+}
+
+// pickRenderer - формат из ?format= важнее заголовка Accept.
+func pickRenderer(r *http.Request) (service.Renderer, int, bool) {
+	if format := r.URL.Query().Get("format"); format != "" {
+		renderer, ok := service.RendererByFormat(format)
+		return renderer, http.StatusBadRequest, ok
+	}
+	renderer, ok := service.NegotiateRenderer(r.Header.Get("Accept"))
+	return renderer, http.StatusNotAcceptable, ok
+}
+
+// pickLegacyRenderer - для старого /links_num: Accept не смотрим, старые клиенты шлют там
+// application/json и ждут PDF. Другой формат - только явным ?format=.
+func pickLegacyRenderer(r *http.Request) (service.Renderer, bool) {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return service.RendererByFormat(format)
+	}
+	return service.RendererByFormat(service.DefaultFormat)
+}
+
+// writeReport - достаёт запросы из хранилища и отдаёт по ним отчёт в формате renderer.
+func writeReport(w http.ResponseWriter, r *http.Request, s storage.Storage, nums []int, renderer service.Renderer, sugar *zap.SugaredLogger) {
+	order, ok := service.ParseReportOrder(r.URL.Query().Get("order"))
+	if !ok {
+		http.Error(w, "unsupported order, use submitted or sorted", http.StatusBadRequest)
+		return
+	}
+
+	// Достаем ссылки из базы данных
+	data, err := s.Get(r.Context(), nums)
+	if err != nil {
+		sugar.Errorf("get links failed: %v", err)
+		http.Error(w, "get links failed", http.StatusInternalServerError)
+		return
+	}
+
+	// Проверю, что все номера нашлись
+	missing := make([]int, 0)
+	for _, n := range nums {
+		if _, ok := data[n]; !ok {
+			missing = append(missing, n)
+		}
+	}
+	if len(missing) > 0 {
+		sugar.Warnw("some request numbers not found", "missing", missing)
+		http.Error(w, "some request numbers not found", http.StatusNotFound)
+		return
+	}
+
+	// Собираем отчёт
+	buf, err := renderer.Render(service.OrderLinks(data, order))
+	if err != nil {
+		sugar.Errorf("create %s report failed: %v", renderer.Extension(), err)
+		http.Error(w, "create report failed", http.StatusInternalServerError)
+		return
+	}
+
+	// Возвращаем ответ
+	w.Header().Set("Content-Type", renderer.ContentType())
+	w.Header().Set("Content-Disposition", "attachment; filename=report."+renderer.Extension())
+	w.Header().Set("Vary", "Accept")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(buf)
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/models"
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestNewGetReport(t *testing.T) {
+	storage := &MockStorage{Data: make(map[int]models.ResponseSentLinks)}
+	ctx := context.Background()
+	for _, n := range []int{1, 2} {
+		require.NoError(t, storage.Save(ctx, models.ResponseSentLinks{
+			Num:   n,
+			Links: []models.CheckResult{{URL: "google.com", Status: models.StatusAvailable}},
+		}))
+	}
+
+	h := NewGetReport(storage, zap.NewNop().Sugar())
+	r := chi.NewRouter()
+	r.Get("/reports", h)
+	r.Get("/reports/{num}", h)
+	r.Get("/links/{num}/report", h)
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+	}{
+		{name: "by path", path: "/reports/1", wantStatus: http.StatusOK},
+		{name: "legacy-style path", path: "/links/2/report", wantStatus: http.StatusOK},
+		{name: "by query", path: "/reports?ids=1,2", wantStatus: http.StatusOK},
+		{name: "query with spaces", path: "/reports?ids=1,%202", wantStatus: http.StatusOK},
+		{name: "not found", path: "/reports/99", wantStatus: http.StatusNotFound},
+		{name: "partly not found", path: "/reports?ids=1,99", wantStatus: http.StatusNotFound},
+		{name: "bad number", path: "/reports/abc", wantStatus: http.StatusBadRequest},
+		{name: "negative number", path: "/reports?ids=-1", wantStatus: http.StatusBadRequest},
+		{name: "no ids", path: "/reports", wantStatus: http.StatusBadRequest},
+		{name: "sorted", path: "/reports/1?order=sorted", wantStatus: http.StatusOK},
+		{name: "unknown order", path: "/reports/1?order=random", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			res := w.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, tt.wantStatus, res.StatusCode)
+			if tt.wantStatus == http.StatusOK {
+				assert.Equal(t, "application/pdf", res.Header.Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestNewGetReport_Formats(t *testing.T) {
+	storage := &MockStorage{Data: make(map[int]models.ResponseSentLinks)}
+	require.NoError(t, storage.Save(context.Background(), models.ResponseSentLinks{
+		Num:   1,
+		Links: []models.CheckResult{{URL: "google.com", Status: models.StatusAvailable}},
+	}))
+
+	r := chi.NewRouter()
+	r.Get("/reports/{num}", NewGetReport(storage, zap.NewNop().Sugar()))
+
+	tests := []struct {
+		name        string
+		path        string
+		accept      string
+		wantStatus  int
+		wantType    string
+		wantFileExt string
+	}{
+		{name: "default pdf", path: "/reports/1", wantStatus: http.StatusOK, wantType: "application/pdf", wantFileExt: "pdf"},
+		{name: "query format", path: "/reports/1?format=csv", accept: "application/json", wantStatus: http.StatusOK, wantType: "text/csv", wantFileExt: "csv"},
+		{name: "accept json", path: "/reports/1", accept: "application/json", wantStatus: http.StatusOK, wantType: "application/json", wantFileExt: "json"},
+		{name: "accept junit", path: "/reports/1", accept: "application/xml", wantStatus: http.StatusOK, wantType: "application/xml", wantFileExt: "xml"},
+		{name: "unknown format", path: "/reports/1?format=docx", wantStatus: http.StatusBadRequest},
+		{name: "not acceptable", path: "/reports/1", accept: "image/png", wantStatus: http.StatusNotAcceptable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			res := w.Result()
+			defer res.Body.Close()
+
+			require.Equal(t, tt.wantStatus, res.StatusCode)
+			if tt.wantStatus == http.StatusOK {
+				assert.Equal(t, tt.wantType, res.Header.Get("Content-Type"))
+				assert.Equal(t, "attachment; filename=report."+tt.wantFileExt, res.Header.Get("Content-Disposition"))
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/models"
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/service"
+)
+
+// maxUploadBytes - предел тела запроса с документами.
+const maxUploadBytes = 10 << 20
+
+var (
+	errInvalidJSON        = errors.New("Invalid JSON format")
+	errInvalidContentType = errors.New("Invalid content type")
+	errNoDocumentLinks    = errors.New("no links found in documents")
+)
+
+// decodeLinksRequest - запрос на проверку из тела любого поддерживаемого вида:
+//   - application/json - RequestSentLinks как есть;
+//   - text/plain, text/markdown, text/html - документ, ссылки из него проверяются с настройками по умолчанию,
+//     "?async=true" включает фоновый режим;
+//   - multipart/form-data - файлы документов плюс необязательное поле options с тем же JSON, что и в первом случае.
+//
+// У ссылок из документов заполнен Source. Документы больше maxUploadBytes не читаются (*http.MaxBytesError).
+func decodeLinksRequest(w http.ResponseWriter, r *http.Request) (models.RequestSentLinks, error) {
+	var req models.RequestSentLinks
+
+	mt, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return req, errInvalidContentType
+	}
+	if mt != "application/json" {
+		r.Body = http.MaxBytesReader(w, r.Body, maxUploadBytes)
+	}
+
+	switch mt {
+	case "application/json":
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return req, fmt.Errorf("%w: %v", errInvalidJSON, err)
+		}
+		return req, nil
+	case "multipart/form-data":
+		return decodeMultipart(r)
+	}
+
+	docType, err := service.DocumentType(mt, "")
+	if err != nil {
+		return req, errInvalidContentType
+	}
+	req.Links, err = service.ExtractDocument(r.Body, docType, "")
+	if err != nil {
+		return req, err
+	}
+	if len(req.Links) == 0 {
+		return req, errNoDocumentLinks
+	}
+	if async := r.URL.Query().Get("async"); async != "" {
+		if req.Async, err = strconv.ParseBool(async); err != nil {
+			return req, fmt.Errorf("invalid async: %q", async)
+		}
+	}
+	return req, nil
+}
+
+// decodeMultipart - файлы из multipart/form-data. Тип файла берётся из его Content-Type или расширения,
+// в Source добавляется имя файла. Поля без файла, кроме options, пропускаются.
+func decodeMultipart(r *http.Request) (models.RequestSentLinks, error) {
+	var req models.RequestSentLinks
+
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return req, errInvalidContentType
+	}
+
+	var docs []models.LinkSpec
+	files := 0
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return req, fmt.Errorf("invalid multipart body: %w", err)
+		}
+
+		if part.FileName() == "" {
+			if part.FormName() == "options" {
+				if err := json.NewDecoder(part).Decode(&req); err != nil {
+					return req, fmt.Errorf("%w: options: %v", errInvalidJSON, err)
+				}
+			}
+			continue
+		}
+
+		docType, err := service.DocumentType(part.Header.Get("Content-Type"), part.FileName())
+		if err != nil {
+			return req, fmt.Errorf("%s: %w", part.FileName(), err)
+		}
+		links, err := service.ExtractDocument(part, docType, part.FileName())
+		if err != nil {
+			return req, fmt.Errorf("%s: %w", part.FileName(), err)
+		}
+		docs = append(docs, links...)
+		files++
+	}
+
+	if files > 0 && len(docs) == 0 {
+		return req, errNoDocumentLinks
+	}
+	req.Links = append(req.Links, docs...)
+	return req, nil
+}
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/models"
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// postDocument - отправляет тело в NewCreateLinks без запущенных воркеров и возвращает ответ и хранилище.
+func postDocument(t *testing.T, target, contentType string, body []byte) (*http.Response, *MockStorage) {
+	t.Helper()
+	storage := &MockStorage{Data: make(map[int]models.ResponseSentLinks)}
+	jobs := service.NewJobQueue(storage, service.NewLinkChecker(service.CheckerConfig{}), 1, 10)
+	handler := NewCreateLinks(jobs, zap.NewNop().Sugar())
+
+	req := httptest.NewRequest(http.MethodPost, target, bytes.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	handler(w, req)
+	return w.Result(), storage
+}
+
+func TestNewCreateLinks_TextDocument(t *testing.T) {
+	res, storage := postDocument(t, "/links?async=true", "text/markdown",
+		[]byte("# Docs\nSee [guide](https://example.com/guide) and example.org.\n"))
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusAccepted, res.StatusCode)
+	require.Contains(t, storage.JobsData, 1)
+	assert.Equal(t, []models.LinkSpec{
+		{URL: "https://example.com/guide", Source: "line 2"},
+		{URL: "example.org", Source: "line 2"},
+	}, storage.JobsData[1].Links)
+}
+
+func TestNewCreateLinks_Multipart(t *testing.T) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	require.NoError(t, mw.WriteField("options", `{"async":true,"links":["ya.ru"],"sort_query":true}`))
+
+	fw, err := mw.CreateFormFile("files", "README.md")
+	require.NoError(t, err)
+	fw.Write([]byte("Home: https://example.com\n"))
+
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", `form-data; name="files"; filename="index.html"`)
+	h.Set("Content-Type", "text/html")
+	fw, err = mw.CreatePart(h)
+	require.NoError(t, err)
+	fw.Write([]byte("<p>\n<a href=\"https://example.org/about\">about</a></p>"))
+	require.NoError(t, mw.Close())
+
+	res, storage := postDocument(t, "/links", mw.FormDataContentType(), body.Bytes())
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusAccepted, res.StatusCode)
+	require.Contains(t, storage.JobsData, 1)
+	job := storage.JobsData[1]
+	assert.True(t, job.Options.SortQuery)
+	assert.Equal(t, []models.LinkSpec{
+		{URL: "ya.ru"},
+		{URL: "https://example.com", Source: "README.md:1"},
+		{URL: "https://example.org/about", Source: "index.html:2 <a href>"},
+	}, job.Links)
+}
+
+func TestNewCreateLinks_BadDocuments(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantStatus  int
+	}{
+		{name: "no links", contentType: "text/plain", body: "nothing to check here", wantStatus: http.StatusBadRequest},
+		{name: "unsupported type", contentType: "application/pdf", body: "%PDF-1.4", wantStatus: http.StatusBadRequest},
+		{name: "too large", contentType: "text/plain", body: strings.Repeat("a", maxUploadBytes+1), wantStatus: http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, _ := postDocument(t, "/links", tt.contentType, []byte(tt.body))
+			defer res.Body.Close()
+			assert.Equal(t, tt.wantStatus, res.StatusCode)
+		})
+	}
+}
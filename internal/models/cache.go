@@ -0,0 +1,13 @@
+package models
+
+// CacheState - откуда взят результат проверки.
+type CacheState string
+
+const (
+	// CacheMiss - ссылку проверяли по сети ради этого запроса.
+	CacheMiss CacheState = "miss"
+	// CacheHit - результат взят из кэша недавних проверок.
+	CacheHit CacheState = "hit"
+	// CacheShared - ту же ссылку в этот момент проверял другой запрос, результат общий.
+	CacheShared CacheState = "shared"
+)
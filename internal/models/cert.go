@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// CertInfo - сведения о сертификате сервера (leaf) для https-ссылок.
+type CertInfo struct {
+	Subject  string    `json:"subject"`
+	Issuer   string    `json:"issuer"`
+	SANs     []string  `json:"sans,omitempty"`
+	NotAfter time.Time `json:"not_after"`
+	// DaysLeft - сколько полных дней осталось до окончания срока, для истёкшего - отрицательное.
+	DaysLeft int `json:"days_left"`
+	// Expiring - срок истекает в пределах окна предупреждения.
+	Expiring bool `json:"expiring,omitempty"`
+	Expired  bool `json:"expired,omitempty"`
+	// VerifyError - ошибка проверки цепочки или имени хоста.
+	VerifyError string `json:"verify_error,omitempty"`
+}
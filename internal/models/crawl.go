@@ -0,0 +1,60 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+// CrawlScope - какие найденные страницы обходить дальше.
+type CrawlScope string
+
+const (
+	// ScopeSameHost - только страницы того же хоста, что и исходная ссылка (по умолчанию).
+	ScopeSameHost CrawlScope = "same_host"
+	// ScopeSameDomain - страницы того же регистрируемого домена, включая поддомены.
+	ScopeSameDomain CrawlScope = "same_domain"
+	// ScopeAny - любые страницы.
+	ScopeAny CrawlScope = "any"
+)
+
+// Valid - известна ли область обхода. Пустая означает ScopeSameHost.
+func (s CrawlScope) Valid() bool {
+	switch s {
+	case "", ScopeSameHost, ScopeSameDomain, ScopeAny:
+		return true
+	}
+	return false
+}
+
+const (
+	// DefaultCrawlDepth - глубина обхода, если в запросе не задана своя.
+	DefaultCrawlDepth = 2
+	// DefaultCrawlPages - сколько страниц обходится за запрос, если не задано своё.
+	DefaultCrawlPages = 100
+)
+
+// CrawlOptions - режим обхода: ссылки со страниц проверяются рекурсивно.
+// Depth - сколько уровней страниц обходить: 1 - проверить ссылки с присланных страниц, 2 - и со страниц, на которые они ведут.
+// Ресурсы (картинки, скрипты, стили) проверяются, но не обходятся.
+type CrawlOptions struct {
+	Depth    int        `json:"depth,omitempty"`
+	MaxPages int        `json:"max_pages,omitempty"`
+	Scope    CrawlScope `json:"scope,omitempty"`
+}
+
+// Validate - проверяет пределы обхода.
+func (c *CrawlOptions) Validate() error {
+	if c == nil {
+		return nil
+	}
+	if c.Depth < 0 {
+		return errors.New("crawl.depth: must not be negative")
+	}
+	if c.MaxPages < 0 {
+		return errors.New("crawl.max_pages: must not be negative")
+	}
+	if !c.Scope.Valid() {
+		return fmt.Errorf("crawl.scope: unknown scope %q", c.Scope)
+	}
+	return nil
+}
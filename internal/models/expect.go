@@ -0,0 +1,98 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// Expectations - проверки содержимого ответа. Без них ссылка проверяется HEAD-запросом,
+// с ними - GET с ограничением на размер тела.
+type Expectations struct {
+	// Status - допустимые коды ответа. Если задан, код из списка считается успехом даже для 4xx/5xx.
+	Status []int `json:"status,omitempty"`
+	// BodyContains / BodyNotContains - подстрока, которая должна быть или не должна быть в теле.
+	BodyContains    string `json:"body_contains,omitempty"`
+	BodyNotContains string `json:"body_not_contains,omitempty"`
+	// BodyMatches / BodyNotMatches - регулярное выражение (синтаксис Go) для тела.
+	BodyMatches    string `json:"body_matches,omitempty"`
+	BodyNotMatches string `json:"body_not_matches,omitempty"`
+	// JSONPath - путь в JSON-ответе вида "data.items[0].status", JSONEquals - ожидаемое значение по нему.
+	JSONPath   string          `json:"json_path,omitempty"`
+	JSONEquals json.RawMessage `json:"json_equals,omitempty"`
+	// MaxBytes - максимальный размер тела ответа.
+	MaxBytes int64 `json:"max_bytes,omitempty"`
+}
+
+// Validate - проверяет, что ожидания можно выполнить: регулярки компилируются, у JSON-пути есть значение.
+func (e *Expectations) Validate() error {
+	if e == nil {
+		return nil
+	}
+	for _, code := range e.Status {
+		if code < 100 || code > 599 {
+			return fmt.Errorf("expect.status: invalid code %d", code)
+		}
+	}
+	if _, err := regexp.Compile(e.BodyMatches); err != nil {
+		return fmt.Errorf("expect.body_matches: %w", err)
+	}
+	if _, err := regexp.Compile(e.BodyNotMatches); err != nil {
+		return fmt.Errorf("expect.body_not_matches: %w", err)
+	}
+	if (e.JSONPath == "") != (len(e.JSONEquals) == 0) {
+		return errors.New("expect: json_path and json_equals go together")
+	}
+	if len(e.JSONEquals) > 0 && !json.Valid(e.JSONEquals) {
+		return errors.New("expect.json_equals: invalid JSON")
+	}
+	if e.MaxBytes < 0 {
+		return errors.New("expect.max_bytes: must be positive")
+	}
+	return nil
+}
+
+// LinkSpec - ссылка на проверку. В JSON это либо строка, либо объект с URL и ожиданиями.
+type LinkSpec struct {
+	URL    string        `json:"url"`
+	Expect *Expectations `json:"expect,omitempty"`
+	// Source - откуда ссылка взята в загруженном документе: файл, строка, элемент.
+	Source string `json:"source,omitempty"`
+}
+
+// LinkSpecs - ссылки без ожиданий.
+func LinkSpecs(urls ...string) []LinkSpec {
+	specs := make([]LinkSpec, len(urls))
+	for i, u := range urls {
+		specs[i] = LinkSpec{URL: u}
+	}
+	return specs
+}
+
+// UnmarshalJSON - принимает и строку, и объект.
+func (l *LinkSpec) UnmarshalJSON(b []byte) error {
+	var url string
+	if err := json.Unmarshal(b, &url); err == nil {
+		*l = LinkSpec{URL: url}
+		return nil
+	}
+
+	// отдельный тип без методов, чтобы не уйти в рекурсию
+	type plain LinkSpec
+	var p plain
+	if err := json.Unmarshal(b, &p); err != nil {
+		return err
+	}
+	*l = LinkSpec(p)
+	return nil
+}
+
+// MarshalJSON - ссылка без ожиданий и места в документе пишется строкой, как раньше.
+func (l LinkSpec) MarshalJSON() ([]byte, error) {
+	if l.Expect == nil && l.Source == "" {
+		return json.Marshal(l.URL)
+	}
+	type plain LinkSpec
+	return json.Marshal(plain(l))
+}
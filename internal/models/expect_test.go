@@ -0,0 +1,71 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinkSpec_JSON(t *testing.T) {
+	raw := `["ya.ru",{"url":"google.com","expect":{"status":[200,404],"body_contains":"ok"}}]`
+
+	var links []LinkSpec
+	require.NoError(t, json.Unmarshal([]byte(raw), &links))
+	require.Len(t, links, 2)
+
+	assert.Equal(t, LinkSpec{URL: "ya.ru"}, links[0])
+	assert.Equal(t, "google.com", links[1].URL)
+	require.NotNil(t, links[1].Expect)
+	assert.Equal(t, []int{200, 404}, links[1].Expect.Status)
+	assert.Equal(t, "ok", links[1].Expect.BodyContains)
+
+	// обратно пишется в том же виде
+	out, err := json.Marshal(links)
+	require.NoError(t, err)
+	assert.JSONEq(t, raw, string(out))
+}
+
+func TestExpectations_Validate(t *testing.T) {
+	var none *Expectations
+	assert.NoError(t, none.Validate())
+
+	tests := []struct {
+		name    string
+		expect  Expectations
+		wantErr bool
+	}{
+		{name: "ok", expect: Expectations{Status: []int{200}, BodyMatches: `v\d+`, JSONPath: "status", JSONEquals: json.RawMessage(`"up"`)}},
+		{name: "bad code", expect: Expectations{Status: []int{42}}, wantErr: true},
+		{name: "bad regex", expect: Expectations{BodyNotMatches: "("}, wantErr: true},
+		{name: "path without value", expect: Expectations{JSONPath: "status"}, wantErr: true},
+		{name: "bad json value", expect: Expectations{JSONPath: "status", JSONEquals: json.RawMessage(`{`)}, wantErr: true},
+		{name: "negative size", expect: Expectations{MaxBytes: -1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.expect.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestJob_JSONKeepsSource(t *testing.T) {
+	job := Job{Num: 3, State: JobQueued, Links: []LinkSpec{
+		{URL: "https://a.example", Source: "docs.md:3"},
+		{URL: "https://b.example"},
+	}}
+
+	out, err := json.Marshal(job)
+	require.NoError(t, err)
+
+	var got Job
+	require.NoError(t, json.Unmarshal(out, &got))
+	assert.Equal(t, job, got)
+}
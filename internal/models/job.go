@@ -0,0 +1,43 @@
+package models
+
+// JobState - состояние фоновой проверки.
+type JobState string
+
+const (
+	// JobQueued - задача ждёт свободного воркера.
+	JobQueued JobState = "queued"
+	// JobRunning - ссылки проверяются.
+	JobRunning JobState = "running"
+	// JobDone - результат сохранён в хранилище под номером задачи.
+	JobDone JobState = "done"
+	// JobFailed - результат не удалось сохранить.
+	JobFailed JobState = "failed"
+)
+
+// Job - фоновая проверка ссылок. Номер задачи совпадает с номером запроса в хранилище.
+type Job struct {
+	Num   int        `json:"links_num"`
+	State JobState   `json:"state"`
+	Links []LinkSpec `json:"links,omitempty"`
+	// Sitemaps - карты сайта, раскрываются воркером перед проверкой, их ссылки идут после Links
+	Sitemaps []string     `json:"sitemaps,omitempty"`
+	Options  CheckOptions `json:"options,omitzero"`
+	Total    int          `json:"total"`
+	Checked  int          `json:"checked"`
+	Error    string       `json:"error,omitempty"`
+}
+
+// JobStatus - задача в ответах API: только состояние и прогресс. Ссылки и настройки нужны для перезапуска
+// и в ответ не попадают: после раскрытия карт сайта ссылок могут быть десятки тысяч.
+type JobStatus struct {
+	Num     int      `json:"links_num"`
+	State   JobState `json:"state"`
+	Total   int      `json:"total"`
+	Checked int      `json:"checked"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// Status - состояние задачи для ответа клиенту.
+func (j Job) Status() JobStatus {
+	return JobStatus{Num: j.Num, State: j.State, Total: j.Total, Checked: j.Checked, Error: j.Error}
+}
@@ -1,14 +1,145 @@
 package models
 
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"slices"
+	"time"
+)
+
 // RequestSentLinks - сущность для приема ссылок на проверку.
+// Если Async выставлен, проверка идёт в фоне, а клиент сразу получает номер задачи.
+// Настройки проверки (CheckOptions) передаются полями верхнего уровня.
+// Ссылка - строка или объект с собственными ожиданиями (LinkSpec).
 type RequestSentLinks struct {
-	Links []string `json:"links"`
+	Links []LinkSpec `json:"links"`
+	Async bool       `json:"async,omitempty"`
+	// Sitemaps - карты сайта, все ссылки из них добавляются к Links.
+	Sitemaps []string `json:"sitemaps,omitempty"`
+	CheckOptions
+}
+
+// CheckResult - подробный результат проверки одной ссылки.
+type CheckResult struct {
+	URL string `json:"url"`
+	// Position - номер ссылки в присланном списке, с 1. 0 - неизвестен (старые записи).
+	Position   int        `json:"position,omitempty"`
+	Status     LinkStatus `json:"status"`
+	StatusCode int        `json:"status_code,omitempty"`
+	FinalURL   string     `json:"final_url,omitempty"`
+	Method     string     `json:"method,omitempty"`
+	LatencyMs  int64      `json:"latency_ms"`
+	// Canonical - каноническая форма ссылки, именно она проверяется.
+	Canonical string `json:"canonical,omitempty"`
+	// DuplicateOf - ссылка из того же запроса с той же канонической формой, результат скопирован с неё.
+	DuplicateOf string `json:"duplicate_of,omitempty"`
+	// Source - место ссылки в загруженном документе (см. LinkSpec.Source).
+	Source string `json:"source,omitempty"`
+	// FoundOn - страницы, на которых ссылка найдена при обходе. Пусто у присланных ссылок.
+	FoundOn []string `json:"found_on,omitempty"`
+	// Depth - на каком уровне обхода найдена ссылка, 0 - присланная.
+	Depth int `json:"depth,omitempty"`
+	// Cache - результат свежий, из кэша или общий с параллельным запросом.
+	Cache CacheState `json:"cache,omitempty"`
+	// Attempts - сколько раз обращались к серверу с учётом повторов при временных ошибках.
+	Attempts  int       `json:"attempts,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checked_at,omitzero"`
+
+	// Redirects - цепочка переходов до FinalURL, по одному элементу на каждый ответ 3xx.
+	Redirects []RedirectHop `json:"redirects,omitempty"`
+	// RedirectLoop - цепочка вернулась на уже посещённый URL.
+	RedirectLoop bool `json:"redirect_loop,omitempty"`
+	// TooManyRedirects - цепочка длиннее допустимой.
+	TooManyRedirects bool `json:"too_many_redirects,omitempty"`
+	// CrossDomain - цепочка ушла на другой домен (например, припаркованный или страницу логина).
+	CrossDomain bool `json:"cross_domain,omitempty"`
+	// HTTPSDowngrade - в цепочке есть переход с https на http.
+	HTTPSDowngrade bool `json:"https_downgrade,omitempty"`
+
+	// AssertionFailures - какие ожидания из Expectations не выполнились.
+	AssertionFailures []string `json:"assertion_failures,omitempty"`
+
+	// Records - найденные DNS-записи для ссылок dns: и mailto:.
+	Records []string `json:"records,omitempty"`
+
+	// Timings - разбивка времени последнего запроса: DNS, подключение, TLS, первый байт.
+	Timings *Timings `json:"timings,omitempty"`
+
+	// Cert - сертификат последнего https-хопа, в том числе не прошедший проверку.
+	Cert *CertInfo `json:"cert,omitempty"`
+}
+
+// UnmarshalJSON - помимо объекта принимает старый формат, где вместо результата лежала строка статуса.
+func (c *CheckResult) UnmarshalJSON(b []byte) error {
+	var status string
+	if err := json.Unmarshal(b, &status); err == nil {
+		*c = CheckResult{Status: ParseLinkStatus(status)}
+		return nil
+	}
+
+	// отдельный тип без методов, чтобы не уйти в рекурсию
+	type plain CheckResult
+	var p plain
+	if err := json.Unmarshal(b, &p); err != nil {
+		return err
+	}
+	*c = CheckResult(p)
+	return nil
 }
 
 // ResponseSentLinks - структура для выдачи обработанных ссылок.
+// Links идут в порядке отправки, повторяющиеся ссылки не схлопываются.
 type ResponseSentLinks struct {
-	Links map[string]string `json:"links"`
-	Num   int               `json:"links_num"`
+	Links []CheckResult `json:"links"`
+	Num   int           `json:"links_num"`
+}
+
+// Find - результат первой ссылки с таким URL, как его прислали.
+func (r ResponseSentLinks) Find(url string) (CheckResult, bool) {
+	for _, res := range r.Links {
+		if res.URL == url {
+			return res, true
+		}
+	}
+	return CheckResult{}, false
+}
+
+// UnmarshalJSON - помимо списка принимает старый формат, где Links был объектом "ссылка -> результат".
+// Исходный порядок там потерян, поэтому такие ссылки выстраиваются по алфавиту.
+func (r *ResponseSentLinks) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Links json.RawMessage `json:"links"`
+		Num   int             `json:"links_num"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	*r = ResponseSentLinks{Num: raw.Num}
+
+	links := bytes.TrimSpace(raw.Links)
+	if len(links) == 0 || links[0] != '{' {
+		if len(links) == 0 || bytes.Equal(links, []byte("null")) {
+			return nil
+		}
+		return json.Unmarshal(links, &r.Links)
+	}
+
+	var legacy map[string]CheckResult
+	if err := json.Unmarshal(links, &legacy); err != nil {
+		return err
+	}
+	urls := slices.Sorted(maps.Keys(legacy))
+	r.Links = make([]CheckResult, 0, len(urls))
+	for _, url := range urls {
+		res := legacy[url]
+		if res.URL == "" {
+			res.URL = url
+		}
+		r.Links = append(r.Links, res)
+	}
+	return nil
 }
 
 // RequestLinksNum - сущность для получения запроса на выдачу ссылок.
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseSentLinks_UnmarshalLegacy(t *testing.T) {
+	raw := `{"links":{"google.com":"available","ya.ru":"not available","go.dev":"avaivable"},"links_num":1}`
+
+	var resp ResponseSentLinks
+	require.NoError(t, json.Unmarshal([]byte(raw), &resp))
+
+	assert.Equal(t, 1, resp.Num)
+	// порядок отправки в старом формате потерян, ссылки идут по алфавиту
+	require.Len(t, resp.Links, 3)
+	assert.Equal(t, CheckResult{URL: "go.dev", Status: StatusAvailable}, resp.Links[0])
+	assert.Equal(t, CheckResult{URL: "google.com", Status: StatusAvailable}, resp.Links[1])
+	assert.Equal(t, CheckResult{URL: "ya.ru", Status: StatusUnreachable}, resp.Links[2])
+}
+
+func TestResponseSentLinks_UnmarshalList(t *testing.T) {
+	raw := `{"links":[{"url":"ya.ru","position":1,"status":"available"},{"url":"ya.ru","position":2,"status":"available"}],"links_num":2}`
+
+	var resp ResponseSentLinks
+	require.NoError(t, json.Unmarshal([]byte(raw), &resp))
+
+	assert.Equal(t, 2, resp.Num)
+	require.Len(t, resp.Links, 2)
+	assert.Equal(t, 2, resp.Links[1].Position)
+
+	res, ok := resp.Find("ya.ru")
+	assert.True(t, ok)
+	assert.Equal(t, 1, res.Position)
+	_, ok = resp.Find("google.com")
+	assert.False(t, ok)
+}
+
+func TestCheckResult_UnmarshalObject(t *testing.T) {
+	raw := `{"url":"https://google.com","status":"available","status_code":200,"method":"HEAD","latency_ms":12}`
+
+	var res CheckResult
+	require.NoError(t, json.Unmarshal([]byte(raw), &res))
+
+	assert.Equal(t, "https://google.com", res.URL)
+	assert.Equal(t, 200, res.StatusCode)
+	assert.Equal(t, "HEAD", res.Method)
+	assert.Equal(t, StatusAvailable, res.Status)
+	assert.Equal(t, int64(12), res.LatencyMs)
+}
@@ -0,0 +1,48 @@
+package models
+
+// RedirectPolicy - как поступать с редиректами при проверке.
+type RedirectPolicy string
+
+const (
+	// RedirectFollow - идти по всей цепочке (по умолчанию).
+	RedirectFollow RedirectPolicy = "follow"
+	// RedirectNone - не переходить, результатом считается первый ответ.
+	RedirectNone RedirectPolicy = "none"
+	// RedirectSameHost - переходить только в пределах исходного хоста.
+	RedirectSameHost RedirectPolicy = "same_host"
+)
+
+// DefaultMaxRedirects - предел длины цепочки, если в запросе не задан свой.
+const DefaultMaxRedirects = 10
+
+// Valid - известна ли политика. Пустая означает RedirectFollow.
+func (p RedirectPolicy) Valid() bool {
+	switch p {
+	case "", RedirectFollow, RedirectNone, RedirectSameHost:
+		return true
+	}
+	return false
+}
+
+// CheckOptions - настройки проверки, задаваемые в запросе на все его ссылки.
+type CheckOptions struct {
+	RedirectPolicy RedirectPolicy `json:"redirect_policy,omitempty"`
+	MaxRedirects   int            `json:"max_redirects,omitempty"`
+	// SortQuery - сортировать параметры запроса при нормализации, чтобы ?a=1&b=2 и ?b=2&a=1 считались одной ссылкой.
+	SortQuery bool `json:"sort_query,omitempty"`
+	// Fresh - проверить заново, не беря результат из кэша.
+	Fresh bool `json:"fresh,omitempty"`
+	// Expect - ожидания для всех ссылок запроса; у ссылки могут быть свои, тогда действуют они.
+	Expect *Expectations `json:"expect,omitempty"`
+	// Crawl - обходить страницы и проверять найденные на них ссылки.
+	Crawl *CrawlOptions `json:"crawl,omitempty"`
+	// Robots - соблюдать robots.txt: не проверять запрещённые ссылки и выдерживать Crawl-delay.
+	Robots bool `json:"robots,omitempty"`
+}
+
+// RedirectHop - один переход в цепочке редиректов.
+type RedirectHop struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"status_code"`
+	Location   string `json:"location"`
+}
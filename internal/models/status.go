@@ -0,0 +1,67 @@
+package models
+
+import "encoding/json"
+
+// LinkStatus - итоговый статус проверки ссылки.
+type LinkStatus string
+
+const (
+	// StatusAvailable - ссылка отвечает 2xx без редиректов.
+	StatusAvailable LinkStatus = "available"
+	// StatusDegraded - ссылка отвечает 2xx, но медленнее допустимого.
+	StatusDegraded LinkStatus = "degraded"
+	// StatusRedirected - ссылка доступна, но ведёт на другой URL.
+	StatusRedirected LinkStatus = "redirected"
+	// StatusClientError - сервер ответил кодом 4xx.
+	StatusClientError LinkStatus = "client_error"
+	// StatusServerError - сервер ответил кодом 5xx.
+	StatusServerError LinkStatus = "server_error"
+	// StatusAnchorMissing - страница доступна, но якоря из #фрагмента на ней нет.
+	StatusAnchorMissing LinkStatus = "anchor_missing"
+	// StatusAssertionFailed - ответ получен, но не прошёл проверки содержимого.
+	StatusAssertionFailed LinkStatus = "assertion_failed"
+	// StatusRedirectError - цепочка редиректов зациклилась или слишком длинная.
+	StatusRedirectError LinkStatus = "redirect_error"
+	// StatusTimeout - не дождались ответа.
+	StatusTimeout LinkStatus = "timeout"
+	// StatusDNSFailure - имя хоста не резолвится.
+	StatusDNSFailure LinkStatus = "dns_failure"
+	// StatusConnectionRefused - хост есть, но порт закрыт.
+	StatusConnectionRefused LinkStatus = "connection_refused"
+	// StatusTLSError - ошибка TLS: недоверенный или просроченный сертификат, неверное имя и т.п.
+	StatusTLSError LinkStatus = "tls_error"
+	// StatusInvalidURL - ссылку не удалось разобрать.
+	StatusInvalidURL LinkStatus = "invalid_url"
+	// StatusDisallowed - robots.txt сайта запрещает проверку ссылки.
+	StatusDisallowed LinkStatus = "disallowed"
+	// StatusBlocked - проверка ссылки запрещена политикой сервиса.
+	StatusBlocked LinkStatus = "blocked"
+	// StatusUnreachable - прочие сетевые ошибки.
+	StatusUnreachable LinkStatus = "unreachable"
+)
+
+// OK - считается ли ссылка рабочей.
+func (s LinkStatus) OK() bool {
+	return s == StatusAvailable || s == StatusDegraded || s == StatusRedirected
+}
+
+// ParseLinkStatus - приводит строку к статусу, включая значения из старых файлов хранилища.
+func ParseLinkStatus(s string) LinkStatus {
+	switch s {
+	case "avaivable":
+		return StatusAvailable
+	case "not available":
+		return StatusUnreachable
+	}
+	return LinkStatus(s)
+}
+
+// UnmarshalJSON - читает статус с учётом старых значений.
+func (s *LinkStatus) UnmarshalJSON(b []byte) error {
+	var raw string
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	*s = ParseLinkStatus(raw)
+	return nil
+}
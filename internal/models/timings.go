@@ -0,0 +1,12 @@
+package models
+
+// Timings - из чего сложилось время последнего запроса к ссылке, в миллисекундах.
+// Для переиспользованного соединения DNS, подключение и TLS равны нулю.
+type Timings struct {
+	DNSMs     int64 `json:"dns_ms"`
+	ConnectMs int64 `json:"connect_ms"`
+	TLSMs     int64 `json:"tls_ms"`
+	// TTFBMs - от отправки запроса до первого байта ответа.
+	TTFBMs  int64 `json:"ttfb_ms"`
+	TotalMs int64 `json:"total_ms"`
+}
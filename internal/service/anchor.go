@@ -0,0 +1,126 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/models"
+)
+
+// linkAnchor - якорь из фрагмента ссылки, который можно проверить на странице.
+// Пусто, если фрагмента нет или это не якорь: маршруты SPA (#/path, #!/path) и текстовые фрагменты (#:~:text=).
+// #top тоже пропускается - он прокручивает в начало страницы и без такого элемента.
+func linkAnchor(link string) string {
+	_, frag, ok := strings.Cut(strings.TrimSpace(link), "#")
+	if !ok || frag == "" || strings.HasPrefix(frag, "/") || strings.HasPrefix(frag, "!") || strings.HasPrefix(frag, ":~:") {
+		return ""
+	}
+	if u, err := url.PathUnescape(frag); err == nil {
+		frag = u
+	}
+	if strings.EqualFold(frag, "top") {
+		return ""
+	}
+	return frag
+}
+
+// anchorEntry - якоря страницы в кэше. html == false - страница не HTML, якоря на ней не проверить.
+type anchorEntry struct {
+	ids     map[string]bool
+	html    bool
+	expires time.Time
+}
+
+// anchorCache - якоря страниц по URL, живут столько же, сколько результаты проверок (CacheTTL).
+type anchorCache struct {
+	mu        sync.Mutex
+	items     map[string]anchorEntry
+	lastSweep time.Time
+}
+
+// set - сохраняет якоря на ttl, заодно раз в ttl выбрасывает протухшие записи (как resultCache.set).
+func (a *anchorCache) set(key string, e anchorEntry, ttl time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(a.lastSweep) > ttl {
+		for k, old := range a.items {
+			if now.After(old.expires) {
+				delete(a.items, k)
+			}
+		}
+		a.lastSweep = now
+	}
+	e.expires = now.Add(ttl)
+	a.items[key] = e
+}
+
+// checkAnchor - для рабочей страницы проверяет, что на ней есть элемент с id (или a с name), равным якорю.
+// Страница скачивается по FinalURL, то есть после редиректов. Если страница не HTML или её не удалось скачать,
+// результат не меняется: проверить якорь нечем, а сама ссылка работает.
+func (c *LinkChecker) checkAnchor(ctx context.Context, res *models.CheckResult, anchor string) {
+	page, err := url.Parse(res.FinalURL)
+	if err != nil || (page.Scheme != "http" && page.Scheme != "https") {
+		return
+	}
+
+	e, ok := c.pageAnchors(ctx, page)
+	if !ok || !e.html {
+		return
+	}
+	if e.ids[anchor] {
+		return
+	}
+	res.Status = models.StatusAnchorMissing
+	res.Error = fmt.Sprintf("anchor #%s not found on page", anchor)
+}
+
+// pageAnchors - якоря страницы из кэша или со страницы. false - страницу скачать не удалось.
+func (c *LinkChecker) pageAnchors(ctx context.Context, page *url.URL) (anchorEntry, bool) {
+	key := page.String()
+
+	c.anchorCache.mu.Lock()
+	e, ok := c.anchorCache.items[key]
+	c.anchorCache.mu.Unlock()
+	if ok && time.Now().Before(e.expires) {
+		return e, true
+	}
+
+	v, err, _ := c.flight.Do("anchors "+key, func() (any, error) {
+		e, err := c.fetchAnchors(ctx, page)
+		if err == nil && c.cfg.CacheTTL > 0 {
+			c.anchorCache.set(key, e, c.cfg.CacheTTL)
+		}
+		return e, err
+	})
+	if err != nil {
+		return anchorEntry{}, false
+	}
+	return v.(anchorEntry), true
+}
+
+// fetchAnchors - скачивает страницу и собирает её якоря.
+func (c *LinkChecker) fetchAnchors(ctx context.Context, page *url.URL) (anchorEntry, error) {
+	var t models.Timings
+	resp, err := c.send(ctx, http.MethodGet, page, &t, maxPageBytes)
+	if err != nil {
+		return anchorEntry{}, err
+	}
+	if resp.StatusCode/100 != 2 {
+		return anchorEntry{}, fmt.Errorf("page returned %s", resp.Status)
+	}
+
+	mt, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || (mt != "text/html" && mt != "application/xhtml+xml") {
+		return anchorEntry{}, nil
+	}
+	return anchorEntry{ids: extractAnchors(bytes.NewReader(resp.Body)), html: true}, nil
+}
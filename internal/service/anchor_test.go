@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinkAnchor(t *testing.T) {
+	tests := map[string]string{
+		"https://example.com/docs":                    "",
+		"https://example.com/docs#":                   "",
+		"https://example.com/docs#install":            "install",
+		"https://example.com/docs#%D0%BF%D1%80%D0%B8": "при",
+		"https://example.com/app#/settings":           "",
+		"https://example.com/app#!/settings":          "",
+		"https://example.com/docs#:~:text=install":    "",
+		"https://example.com/docs#TOP":                "",
+	}
+	for link, want := range tests {
+		assert.Equal(t, want, linkAnchor(link), link)
+	}
+}
+
+func TestCheckLink_Anchor(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/docs":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte(`<html><body><h2 id="install">Install</h2><a name="legacy"></a></body></html>`))
+		case "/moved":
+			http.Redirect(w, r, "/docs", http.StatusMovedPermanently)
+		case "/file.txt":
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte("id=\"install\""))
+		}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name string
+		link string
+		want models.LinkStatus
+	}{
+		{name: "id", link: srv.URL + "/docs#install", want: models.StatusAvailable},
+		{name: "a name", link: srv.URL + "/docs#legacy", want: models.StatusAvailable},
+		{name: "missing", link: srv.URL + "/docs#missing", want: models.StatusAnchorMissing},
+		{name: "after redirect", link: srv.URL + "/moved#nope", want: models.StatusAnchorMissing},
+		{name: "spa route", link: srv.URL + "/docs#/missing", want: models.StatusAvailable},
+		{name: "not html", link: srv.URL + "/file.txt#missing", want: models.StatusAvailable},
+	}
+
+	c := newTestChecker()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := c.CheckLink(context.Background(), tt.link, models.CheckOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, res.Status)
+			if tt.want == models.StatusAnchorMissing {
+				assert.Contains(t, res.Error, "not found on page")
+			}
+		})
+	}
+}
+
+func TestCheckAll_AnchorsNotDuplicates(t *testing.T) {
+	var pageCalls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			pageCalls.Add(1)
+		}
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<p id="a">a</p>`))
+	}))
+	defer srv.Close()
+
+	c := NewLinkChecker(CheckerConfig{Allow: testAllow, CacheTTL: time.Minute})
+	results := c.CheckAll(context.Background(), models.LinkSpecs(srv.URL+"/#a", srv.URL+"/#b", srv.URL+"/#a"), models.CheckOptions{})
+	require.Len(t, results, 3)
+
+	assert.Equal(t, models.StatusAvailable, results[0].Status)
+	assert.Empty(t, results[1].DuplicateOf)
+	assert.Equal(t, models.StatusAnchorMissing, results[1].Status)
+	assert.Equal(t, srv.URL+"/#a", results[2].DuplicateOf)
+
+	// якоря обеих ссылок берутся с одной скачанной страницы
+	assert.Equal(t, int32(1), pageCalls.Load())
+}
+
+func TestAnchorCache_SweepsExpired(t *testing.T) {
+	c := anchorCache{items: map[string]anchorEntry{
+		"https://example.com/old": {html: true, expires: time.Now().Add(-time.Second)},
+	}}
+
+	c.set("https://example.com/new", anchorEntry{html: true}, time.Minute)
+
+	assert.NotContains(t, c.items, "https://example.com/old")
+	assert.Contains(t, c.items, "https://example.com/new")
+}
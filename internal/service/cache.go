@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/models"
+)
+
+// cacheEntry - результат и время, до которого он считается свежим.
+type cacheEntry struct {
+	res     models.CheckResult
+	err     error
+	expires time.Time
+}
+
+// resultCache - TTL-кэш результатов проверки. Протухшие записи вычищаются при записи, не чаще раза в ttl.
+type resultCache struct {
+	ttl time.Duration
+
+	mu        sync.Mutex
+	items     map[string]cacheEntry
+	lastSweep time.Time
+}
+
+func newResultCache(ttl time.Duration) *resultCache {
+	return &resultCache{
+		ttl:       ttl,
+		items:     make(map[string]cacheEntry),
+		lastSweep: time.Now(),
+	}
+}
+
+// get - свежий результат по ключу вместе с ошибкой исходной проверки.
+func (c *resultCache) get(key string) (models.CheckResult, error, bool) {
+	if c.ttl <= 0 {
+		return models.CheckResult{}, nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.items[key]
+	if !ok || time.Now().After(e.expires) {
+		return models.CheckResult{}, nil, false
+	}
+	return e.res, e.err, true
+}
+
+// set - сохраняет результат на ttl.
+func (c *resultCache) set(key string, res models.CheckResult, err error) {
+	if c.ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(c.lastSweep) > c.ttl {
+		for k, e := range c.items {
+			if now.After(e.expires) {
+				delete(c.items, k)
+			}
+		}
+		c.lastSweep = now
+	}
+	c.items[key] = cacheEntry{res: res, err: err, expires: now.Add(c.ttl)}
+}
+
+// cacheKey - ключ кэша: каноническая ссылка плюс настройки, от которых зависит результат.
+func cacheKey(canonical string, opts models.CheckOptions) string {
+	// Fresh влияет только на чтение кэша, Crawl - на то, какие ссылки проверяются; на сам результат - нет
+	opts.Fresh = false
+	opts.Crawl = nil
+	o, _ := json.Marshal(opts)
+	return canonical + " " + string(o)
+}
+
+// CheckLink - проверяет ссылку и возвращает подробный результат.
+// Ссылка сначала приводится к канонической форме (NormalizeURL), проверяется и кэшируется именно она,
+// а в результате остаются обе записи. Недавние результаты берутся из кэша (если не задан opts.Fresh), а одновременные
+// проверки одной и той же ссылки из разных запросов выполняются одним сетевым вызовом.
+// Откуда взят результат, видно по полю Cache.
+// Фрагмент в каноническую форму не входит: якорь проверяется отдельно, уже на рабочей странице (см. checkAnchor).
+func (c *LinkChecker) CheckLink(ctx context.Context, link string, opts models.CheckOptions) (models.CheckResult, error) {
+	res, err := c.checkCached(ctx, link, opts)
+	if err == nil && res.Status.OK() {
+		if anchor := linkAnchor(link); anchor != "" {
+			c.checkAnchor(ctx, &res, anchor)
+		}
+	}
+	return res, err
+}
+
+// checkCached - проверка канонической формы ссылки через кэш и объединение одновременных проверок.
+func (c *LinkChecker) checkCached(ctx context.Context, link string, opts models.CheckOptions) (models.CheckResult, error) {
+	canonical, err := NormalizeURL(link, opts.SortQuery)
+	if err != nil {
+		res := models.CheckResult{
+			URL:       link,
+			Status:    models.StatusInvalidURL,
+			Error:     err.Error(),
+			CheckedAt: time.Now().UTC(),
+		}
+		return res, err
+	}
+	key := cacheKey(canonical, opts)
+
+	if !opts.Fresh {
+		if res, err, ok := c.cache.get(key); ok {
+			res.URL = link
+			res.Canonical = canonical
+			res.Cache = models.CacheHit
+			return res, err
+		}
+	}
+
+	// проверка отвязана от контекста вызвавшего: если он уйдёт, остальные всё равно получат результат.
+	// Сверху её ограничивает тот же общий дедлайн, что и пачку.
+	var leader bool
+	ch := c.flight.DoChan(key, func() (any, error) {
+		leader = true
+		checkCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.cfg.Timeout)
+		defer cancel()
+
+		res, err := c.checkLink(checkCtx, canonical, opts)
+		// результат, оборванный дедлайном, в кэш не кладём
+		if checkCtx.Err() == nil {
+			c.cache.set(key, res, err)
+		}
+		return res, err
+	})
+
+	select {
+	case r := <-ch:
+		res := r.Val.(models.CheckResult)
+		res.URL = link
+		res.Canonical = canonical
+		res.Cache = models.CacheShared
+		if leader {
+			res.Cache = models.CacheMiss
+		}
+		return res, r.Err
+	case <-ctx.Done():
+		res := models.CheckResult{
+			URL:       link,
+			Canonical: canonical,
+			Status:    classifyError(ctx.Err()),
+			Error:     ctx.Err().Error(),
+			CheckedAt: time.Now().UTC(),
+		}
+		return res, ctx.Err()
+	}
+}
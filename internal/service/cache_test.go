@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingServer - сервер, который считает запросы и отвечает после delay.
+func countingServer(calls *atomic.Int32, delay time.Duration) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		time.Sleep(delay)
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func TestCheckLink_CacheHit(t *testing.T) {
+	var calls atomic.Int32
+	srv := countingServer(&calls, 0)
+	defer srv.Close()
+
+	checker := NewLinkChecker(CheckerConfig{Allow: testAllow, CacheTTL: time.Minute})
+
+	res, err := checker.CheckLink(context.Background(), srv.URL, models.CheckOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, models.CacheMiss, res.Cache)
+
+	// та же ссылка в другой записи
+	res, err = checker.CheckLink(context.Background(), srv.URL+"/#top", models.CheckOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, models.CacheHit, res.Cache)
+	assert.Equal(t, srv.URL+"/#top", res.URL)
+	assert.Equal(t, models.StatusAvailable, res.Status)
+	assert.Equal(t, int32(1), calls.Load())
+
+	// fresh идёт в сеть и обновляет кэш
+	res, err = checker.CheckLink(context.Background(), srv.URL, models.CheckOptions{Fresh: true})
+	require.NoError(t, err)
+	assert.Equal(t, models.CacheMiss, res.Cache)
+	assert.Equal(t, int32(2), calls.Load())
+
+	// другие настройки - другой результат
+	res, err = checker.CheckLink(context.Background(), srv.URL, models.CheckOptions{RedirectPolicy: models.RedirectNone})
+	require.NoError(t, err)
+	assert.Equal(t, models.CacheMiss, res.Cache)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestCheckLink_NoCacheByDefault(t *testing.T) {
+	var calls atomic.Int32
+	srv := countingServer(&calls, 0)
+	defer srv.Close()
+
+	checker := newTestChecker()
+	for range 2 {
+		res, err := checker.CheckLink(context.Background(), srv.URL, models.CheckOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, models.CacheMiss, res.Cache)
+	}
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestCheckLink_InFlightDedup(t *testing.T) {
+	var calls atomic.Int32
+	srv := countingServer(&calls, 100*time.Millisecond)
+	defer srv.Close()
+
+	checker := newTestChecker()
+
+	results := make([]models.CheckResult, 5)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = checker.CheckLink(context.Background(), srv.URL, models.CheckOptions{})
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+	states := map[models.CacheState]int{}
+	for _, res := range results {
+		assert.Equal(t, models.StatusAvailable, res.Status)
+		states[res.Cache]++
+	}
+	assert.Equal(t, map[models.CacheState]int{models.CacheMiss: 1, models.CacheShared: 4}, states)
+}
+
+func TestCheckLink_CallerGoneCheckContinues(t *testing.T) {
+	var calls atomic.Int32
+	srv := countingServer(&calls, 100*time.Millisecond)
+	defer srv.Close()
+
+	checker := NewLinkChecker(CheckerConfig{Allow: testAllow, CacheTTL: time.Minute})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	res, err := checker.CheckLink(ctx, srv.URL, models.CheckOptions{})
+	require.Error(t, err)
+	assert.Equal(t, models.StatusTimeout, res.Status)
+
+	// проверка дошла до конца без ушедшего клиента и попала в кэш
+	require.Eventually(t, func() bool {
+		res, _ := checker.CheckLink(context.Background(), srv.URL, models.CheckOptions{})
+		return res.Cache == models.CacheHit
+	}, time.Second, 20*time.Millisecond)
+	assert.Equal(t, int32(1), calls.Load())
+}
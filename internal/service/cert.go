@@ -0,0 +1,81 @@
+package service
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/models"
+)
+
+// certFromResponse - сведения о сертификате из успешного https-ответа.
+func (c *LinkChecker) certFromResponse(resp *http.Response, now time.Time) *models.CertInfo {
+	if resp == nil || resp.TLS == nil || len(resp.TLS.PeerCertificates) == 0 {
+		return nil
+	}
+	return c.certInfo(resp.TLS.PeerCertificates[0], now)
+}
+
+// certFromError - сведения о сертификате, который не прошёл проверку.
+// Сам сертификат лежит внутри ошибки, второй раз подключаться не нужно.
+func (c *LinkChecker) certFromError(err error, now time.Time) *models.CertInfo {
+	var (
+		cert       *x509.Certificate
+		verifyErr  *tls.CertificateVerificationError
+		hostErr    x509.HostnameError
+		unknownErr x509.UnknownAuthorityError
+		invalidErr x509.CertificateInvalidError
+	)
+	switch {
+	case errors.As(err, &verifyErr) && len(verifyErr.UnverifiedCertificates) > 0:
+		cert = verifyErr.UnverifiedCertificates[0]
+	case errors.As(err, &hostErr):
+		cert = hostErr.Certificate
+	case errors.As(err, &unknownErr):
+		cert = unknownErr.Cert
+	case errors.As(err, &invalidErr):
+		cert = invalidErr.Cert
+	}
+	if cert == nil {
+		return nil
+	}
+
+	info := c.certInfo(cert, now)
+	info.VerifyError = err.Error()
+	if verifyErr != nil {
+		info.VerifyError = verifyErr.Err.Error()
+	}
+	return info
+}
+
+// certInfo - основные поля сертификата и оставшийся срок.
+func (c *LinkChecker) certInfo(cert *x509.Certificate, now time.Time) *models.CertInfo {
+	subject := cert.Subject.CommonName
+	if subject == "" {
+		subject = cert.Subject.String()
+	}
+	issuer := cert.Issuer.CommonName
+	if issuer == "" {
+		issuer = cert.Issuer.String()
+	}
+
+	sans := append([]string(nil), cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+
+	left := cert.NotAfter.Sub(now)
+	info := &models.CertInfo{
+		Subject:  subject,
+		Issuer:   issuer,
+		SANs:     sans,
+		NotAfter: cert.NotAfter.UTC(),
+		DaysLeft: int(math.Floor(left.Hours() / 24)),
+		Expired:  left < 0,
+	}
+	info.Expiring = !info.Expired && left <= c.cfg.CertExpiryWindow
+	return info
+}
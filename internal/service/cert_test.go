@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCertInfo_Expiry(t *testing.T) {
+	now := time.Date(2025, 11, 11, 10, 0, 0, 0, time.UTC)
+	c := NewLinkChecker(CheckerConfig{CertExpiryWindow: 7 * 24 * time.Hour})
+
+	cert := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "example.com"},
+		Issuer:      pkix.Name{CommonName: "Test CA"},
+		DNSNames:    []string{"example.com", "www.example.com"},
+		IPAddresses: []net.IP{net.ParseIP("93.184.216.34")},
+		NotAfter:    now.Add(36 * time.Hour),
+	}
+	info := c.certInfo(cert, now)
+	assert.Equal(t, "example.com", info.Subject)
+	assert.Equal(t, "Test CA", info.Issuer)
+	assert.Equal(t, []string{"example.com", "www.example.com", "93.184.216.34"}, info.SANs)
+	assert.Equal(t, 1, info.DaysLeft)
+	assert.True(t, info.Expiring)
+	assert.False(t, info.Expired)
+
+	cert.NotAfter = now.Add(90 * 24 * time.Hour)
+	info = c.certInfo(cert, now)
+	assert.Equal(t, 90, info.DaysLeft)
+	assert.False(t, info.Expiring)
+
+	cert.NotAfter = now.Add(-time.Hour)
+	info = c.certInfo(cert, now)
+	assert.Equal(t, -1, info.DaysLeft)
+	assert.True(t, info.Expired)
+	assert.False(t, info.Expiring)
+}
+
+func TestCheckLink_RecordsCertificate(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	// окно заведомо больше срока тестового сертификата
+	checker := NewLinkChecker(CheckerConfig{Allow: testAllow, CertExpiryWindow: 200 * 365 * 24 * time.Hour})
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.Certificate())
+	checker.client.Transport.(*http.Transport).TLSClientConfig.RootCAs = pool
+
+	res, err := checker.CheckLink(context.Background(), srv.URL, models.CheckOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, models.StatusAvailable, res.Status)
+	require.NotNil(t, res.Cert)
+	assert.Contains(t, res.Cert.SANs, "127.0.0.1")
+	assert.Equal(t, srv.Certificate().NotAfter.UTC(), res.Cert.NotAfter)
+	assert.True(t, res.Cert.Expiring)
+	assert.Empty(t, res.Cert.VerifyError)
+}
+
+func TestCheckLink_RecordsUntrustedCertificate(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	res, err := newTestChecker().CheckLink(context.Background(), srv.URL, models.CheckOptions{})
+	require.Error(t, err)
+
+	assert.Equal(t, models.StatusTLSError, res.Status)
+	require.NotNil(t, res.Cert)
+	assert.Contains(t, res.Cert.SANs, "127.0.0.1")
+	assert.Contains(t, res.Cert.VerifyError, "unknown authority")
+	assert.False(t, res.Cert.Expiring)
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/models"
+	"golang.org/x/sync/singleflight"
+)
+
+// CheckerConfig - настройки пула проверки ссылок.
+type CheckerConfig struct {
+	// Workers - количество одновременных проверок.
+	Workers int
+	// Timeout - общий дедлайн на проверку всех ссылок запроса.
+	Timeout time.Duration
+	// RequestTimeout - таймаут одного HTTP-запроса.
+	RequestTimeout time.Duration
+	// Retry - повторы при временных ошибках.
+	Retry RetryPolicy
+	// HostLimits - ограничения нагрузки на один хост.
+	HostLimits HostLimits
+	// Allow - внутренние сети и хосты, которые всё же можно проверять.
+	Allow Allowlist
+	// CertExpiryWindow - за сколько до окончания срока сертификат помечается как истекающий.
+	CertExpiryWindow time.Duration
+	// SlowThreshold - ответ медленнее этого порога помечается как degraded, 0 - не помечать.
+	SlowThreshold time.Duration
+	// CacheTTL - сколько хранить результаты проверок, 0 - не кэшировать.
+	// Одновременные проверки одной ссылки объединяются в любом случае.
+	CacheTTL time.Duration
+	// Resolver - DNS для ссылок dns: и mailto:, nil - системный.
+	Resolver Resolver
+	// UserAgent - заголовок User-Agent запросов; первое слово (до "/") ищется в robots.txt.
+	UserAgent string
+	// MaxCrawlDelay - больше этой паузы из Crawl-delay не ждём, даже если сайт просит.
+	MaxCrawlDelay time.Duration
+}
+
+// DefaultUserAgent - User-Agent, если в настройках не задан свой.
+const DefaultUserAgent = "linkchecker/1.0"
+
+// LinkChecker - проверяет ссылки пулом воркеров.
+// Проверка конкретной ссылки выбирается по схеме из реестра checkers (см. Register).
+type LinkChecker struct {
+	cfg      CheckerConfig
+	client   *http.Client
+	dial     dialFunc
+	limiter  *HostLimiter
+	cache    *resultCache
+	flight   singleflight.Group
+	checkers map[string]Checker
+	// robotsCache - robots.txt сайтов для проверок с opts.Robots.
+	robotsCache robotsCache
+	// anchorCache - якоря страниц для ссылок с #фрагментом.
+	anchorCache anchorCache
+}
+
+// dialFunc - подключение с учётом сетевой политики (см. guardedDialer).
+type dialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// NewLinkChecker - создает LinkChecker. Нулевые значения заменяются значениями по умолчанию.
+func NewLinkChecker(cfg CheckerConfig) *LinkChecker {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 10
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+	if cfg.RequestTimeout <= 0 {
+		cfg.RequestTimeout = 5 * time.Second
+	}
+	if cfg.CertExpiryWindow <= 0 {
+		cfg.CertExpiryWindow = 14 * 24 * time.Hour
+	}
+	if cfg.MaxCrawlDelay <= 0 {
+		cfg.MaxCrawlDelay = 10 * time.Second
+	}
+	if cfg.UserAgent == "" {
+		cfg.UserAgent = DefaultUserAgent
+	}
+	if cfg.Resolver == nil {
+		cfg.Resolver = net.DefaultResolver
+	}
+	cfg.Retry = cfg.Retry.withDefaults()
+
+	dial := guardedDialer(cfg.Allow, cfg.RequestTimeout)
+	c := &LinkChecker{
+		cfg:      cfg,
+		dial:     dial,
+		limiter:  NewHostLimiter(cfg.HostLimits),
+		cache:    newResultCache(cfg.CacheTTL),
+		checkers: make(map[string]Checker),
+		robotsCache: robotsCache{
+			items: make(map[string]robotsEntry),
+		},
+		anchorCache: anchorCache{
+			items: make(map[string]anchorEntry),
+		},
+		// client не ходит по редиректам сам: цепочка разбирается в CheckLink, чтобы записать каждый переход.
+		client: &http.Client{
+			Timeout:   cfg.RequestTimeout,
+			Transport: newTransport(dial),
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+	c.registerDefaults()
+	return c
+}
+
+// newTransport - транспорт с защитой от SSRF на уровне подключения.
+// Прокси не используется: через него guard не увидел бы настоящий адрес.
+func newTransport(dial dialFunc) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = nil
+	t.DialContext = dial
+	return t
+}
+
+// linkOptions - настройки для одной ссылки: её ожидания важнее общих.
+func linkOptions(opts models.CheckOptions, link models.LinkSpec) models.CheckOptions {
+	if link.Expect != nil {
+		opts.Expect = link.Expect
+	}
+	return opts
+}
+
+// CheckAll - проверяет ссылки параллельно и возвращает результаты в том же порядке, что и links.
+// Вся пачка ограничена общим дедлайном, поэтому время ответа примерно равно времени самой медленной ссылки.
+func (c *LinkChecker) CheckAll(ctx context.Context, links []models.LinkSpec, opts models.CheckOptions) []models.CheckResult {
+	return c.CheckAllWithProgress(ctx, links, opts, nil)
+}
+
+// CheckAllWithProgress - то же, что CheckAll, но вызывает progress после каждой проверенной ссылки.
+// progress может вызываться из разных горутин.
+// Ссылки с одинаковой канонической формой (и одинаковыми ожиданиями и якорем) проверяются один раз:
+// повторы получают копию результата первой из них и отметку DuplicateOf.
+// Ожидания ссылки заменяют ожидания из opts.
+func (c *LinkChecker) CheckAllWithProgress(ctx context.Context, links []models.LinkSpec, opts models.CheckOptions, progress func()) []models.CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+
+	results := make([]models.CheckResult, len(links))
+
+	// индекс первой ссылки с той же канонической формой
+	dupOf := make(map[int]int)
+	first := make(map[string]int)
+	unique := make([]int, 0, len(links))
+	for i, link := range links {
+		canonical, err := NormalizeURL(link.URL, opts.SortQuery)
+		if err == nil {
+			// у ссылок на разные якоря одной страницы результат разный
+			key := cacheKey(canonical, linkOptions(opts, link)) + "#" + linkAnchor(link.URL)
+			if j, ok := first[key]; ok {
+				dupOf[i] = j
+				continue
+			}
+			first[key] = i
+		}
+		unique = append(unique, i)
+	}
+
+	c.runPool(len(unique), func(k int) {
+		i := unique[k]
+		// каждый воркер пишет только в свою ячейку слайса, гонки нет
+		// ошибка уже записана в результат
+		results[i], _ = c.CheckLink(ctx, links[i].URL, linkOptions(opts, links[i]))
+		if progress != nil {
+			progress()
+		}
+	})
+
+	for _, i := range unique {
+		results[i].Position = i + 1
+		results[i].Source = links[i].Source
+	}
+	for i, j := range dupOf {
+		res := results[j]
+		res.URL = links[i].URL
+		res.DuplicateOf = links[j].URL
+		res.Position = i + 1
+		res.Source = links[i].Source
+		results[i] = res
+		if progress != nil {
+			progress()
+		}
+	}
+
+	return results
+}
+
+// runPool - выполняет fn для индексов 0..n-1 не более чем в c.cfg.Workers горутин.
+func (c *LinkChecker) runPool(n int, fn func(i int)) {
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(c.cfg.Workers, n) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fn(i)
+			}
+		}()
+	}
+
+	for i := range n {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinkChecker_CheckAllKeepsOrder(t *testing.T) {
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ok.Close()
+
+	notFound := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer notFound.Close()
+
+	checker := NewLinkChecker(CheckerConfig{Workers: 2, Allow: testAllow})
+	links := models.LinkSpecs(ok.URL, notFound.URL, "", ok.URL)
+
+	res := checker.CheckAll(context.Background(), links, models.CheckOptions{})
+	require.Len(t, res, len(links))
+
+	for i, r := range res {
+		assert.Equal(t, links[i].URL, r.URL)
+	}
+	assert.Equal(t, models.StatusAvailable, res[0].Status)
+	assert.Equal(t, models.StatusClientError, res[1].Status)
+	assert.NotEmpty(t, res[2].Error)
+	assert.Equal(t, models.StatusAvailable, res[3].Status)
+}
+
+func TestLinkChecker_CheckAllRunsConcurrently(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cur := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			prev := maxInFlight.Load()
+			if cur <= prev || maxInFlight.CompareAndSwap(prev, cur) {
+				break
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+	}))
+	defer srv.Close()
+
+	links := make([]models.LinkSpec, 8)
+	// разные пути: одинаковые ссылки проверялись бы одним запросом
+	for i := range links {
+		links[i] = models.LinkSpec{URL: fmt.Sprintf("%s/%d", srv.URL, i)}
+	}
+
+	checker := NewLinkChecker(CheckerConfig{Workers: 4, Allow: testAllow})
+	checker.CheckAll(context.Background(), links, models.CheckOptions{})
+
+	assert.Greater(t, maxInFlight.Load(), int32(1))
+	assert.LessOrEqual(t, maxInFlight.Load(), int32(4))
+}
+
+func TestLinkChecker_CheckAllDeadline(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	}))
+	defer slow.Close()
+
+	checker := NewLinkChecker(CheckerConfig{Workers: 1, Timeout: 100 * time.Millisecond, Allow: testAllow})
+
+	start := time.Now()
+	res := checker.CheckAll(context.Background(), models.LinkSpecs(slow.URL, slow.URL, slow.URL), models.CheckOptions{})
+	assert.Less(t, time.Since(start), time.Second)
+
+	for _, r := range res {
+		assert.Equal(t, models.StatusTimeout, r.Status)
+		assert.NotEmpty(t, r.Error)
+	}
+}
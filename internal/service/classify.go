@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"syscall"
+
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/models"
+)
+
+// classifyError - определяет статус ссылки по ошибке HTTP-клиента.
+func classifyError(err error) models.LinkStatus {
+	if err == nil {
+		return models.StatusAvailable
+	}
+
+	if errors.Is(err, ErrBlocked) {
+		return models.StatusBlocked
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		if dnsErr.IsTimeout {
+			return models.StatusTimeout
+		}
+		return models.StatusDNSFailure
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return models.StatusConnectionRefused
+	}
+
+	if isTLSError(err) {
+		return models.StatusTLSError
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return models.StatusTimeout
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return models.StatusTimeout
+	}
+
+	return models.StatusUnreachable
+}
+
+// isTLSError - ошибки рукопожатия и проверки сертификата.
+func isTLSError(err error) bool {
+	var (
+		verifyErr    *tls.CertificateVerificationError
+		unknownErr   x509.UnknownAuthorityError
+		hostErr      x509.HostnameError
+		invalidErr   x509.CertificateInvalidError
+		recordHdrErr tls.RecordHeaderError
+		alertErr     tls.AlertError
+	)
+	return errors.As(err, &verifyErr) ||
+		errors.As(err, &unknownErr) ||
+		errors.As(err, &hostErr) ||
+		errors.As(err, &invalidErr) ||
+		errors.As(err, &recordHdrErr) ||
+		errors.As(err, &alertErr)
+}
+
+// classifyResponse - определяет статус ссылки по коду ответа и факту редиректа.
+func classifyResponse(code int, redirected bool) models.LinkStatus {
+	switch {
+	case code >= 500:
+		return models.StatusServerError
+	case code >= 400:
+		return models.StatusClientError
+	case code >= 300 || redirected:
+		return models.StatusRedirected
+	case code >= 200:
+		return models.StatusAvailable
+	}
+	return models.StatusUnreachable
+}
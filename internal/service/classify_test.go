@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want models.LinkStatus
+	}{
+		{
+			name: "dns",
+			err:  &url.Error{Op: "Head", URL: "https://nope.invalid", Err: &net.DNSError{Err: "no such host", Name: "nope.invalid", IsNotFound: true}},
+			want: models.StatusDNSFailure,
+		},
+		{
+			name: "dns timeout",
+			err:  &net.DNSError{Err: "i/o timeout", IsTimeout: true},
+			want: models.StatusTimeout,
+		},
+		{
+			name: "connection refused",
+			err:  &url.Error{Op: "Head", Err: &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}},
+			want: models.StatusConnectionRefused,
+		},
+		{
+			name: "unknown authority",
+			err:  &url.Error{Op: "Head", Err: x509.UnknownAuthorityError{}},
+			want: models.StatusTLSError,
+		},
+		{
+			name: "hostname mismatch",
+			err:  &url.Error{Op: "Head", Err: x509.HostnameError{Host: "example.com", Certificate: &x509.Certificate{}}},
+			want: models.StatusTLSError,
+		},
+		{
+			name: "deadline",
+			err:  fmt.Errorf("head: %w", context.DeadlineExceeded),
+			want: models.StatusTimeout,
+		},
+		{
+			name: "other",
+			err:  errors.New("connection reset by peer"),
+			want: models.StatusUnreachable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, classifyError(tt.err))
+		})
+	}
+}
+
+func TestClassifyResponse(t *testing.T) {
+	assert.Equal(t, models.StatusAvailable, classifyResponse(200, false))
+	assert.Equal(t, models.StatusRedirected, classifyResponse(200, true))
+	assert.Equal(t, models.StatusRedirected, classifyResponse(301, false))
+	assert.Equal(t, models.StatusClientError, classifyResponse(404, false))
+	assert.Equal(t, models.StatusServerError, classifyResponse(503, true))
+}
+
+func TestCheckLink_ConnectionRefused(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	addr := srv.URL
+	srv.Close()
+
+	res, err := newTestChecker().CheckLink(context.Background(), addr, models.CheckOptions{})
+	require.Error(t, err)
+	assert.Equal(t, models.StatusConnectionRefused, res.Status)
+}
+
+func TestCheckLink_TLSError(t *testing.T) {
+	// сертификат тестового сервера не доверен системой
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	res, err := newTestChecker().CheckLink(context.Background(), srv.URL, models.CheckOptions{})
+	require.Error(t, err)
+	assert.Equal(t, models.StatusTLSError, res.Status)
+}
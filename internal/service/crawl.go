@@ -0,0 +1,248 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"mime"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/models"
+)
+
+// maxPageBytes - сколько HTML читается с одной страницы при обходе.
+const maxPageBytes = 2 << 20
+
+// crawlItem - ссылка в очереди обхода.
+type crawlItem struct {
+	// idx - номер результата ссылки.
+	idx  int
+	link models.LinkSpec
+	// page - ссылка на страницу (присланная или из a[href]), её можно обходить дальше.
+	page bool
+	// root - присланная ссылка, от которой начался обход; от неё считается область обхода.
+	root *url.URL
+}
+
+// Crawl - проверяет присланные ссылки и рекурсивно ссылки со страниц, на которые они ведут.
+// Обход идёт по уровням: сначала присланные ссылки, потом найденные на них и так далее до opts.Crawl.Depth.
+// Дальше обходятся только рабочие HTML-страницы в пределах opts.Crawl.Scope, всего не больше opts.Crawl.MaxPages.
+// Каждая ссылка проверяется один раз, а в FoundOn копятся все страницы, где она встретилась.
+// Ожидания действуют только на присланные ссылки: к картинкам и скриптам с чужих страниц они обычно не подходят.
+// progress получает число проверенных ссылок и сколько их найдено на данный момент.
+func (c *LinkChecker) Crawl(ctx context.Context, links []models.LinkSpec, opts models.CheckOptions, progress func(checked, total int)) []models.CheckResult {
+	limits := crawlLimits(opts.Crawl)
+	opts.Crawl = nil
+
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+
+	results := make([]models.CheckResult, 0, len(links))
+	// номер результата по канонической форме ссылки
+	seen := make(map[string]int)
+	// повторы среди присланных ссылок: номер результата -> номер первой такой ссылки
+	dupOf := make(map[int]int)
+
+	level := make([]crawlItem, 0, len(links))
+	for _, link := range links {
+		idx := len(results)
+		results = append(results, models.CheckResult{URL: link.URL, Position: idx + 1, Source: link.Source})
+
+		key, root := c.crawlKey(link.URL, opts)
+		// ненормализуемые присланные ссылки не схлопываются, как и в CheckAll
+		if root != nil {
+			if j, ok := seen[key]; ok {
+				dupOf[idx] = j
+				continue
+			}
+			seen[key] = idx
+		}
+		level = append(level, crawlItem{idx: idx, link: link, page: true, root: root})
+	}
+
+	var (
+		mu sync.Mutex
+		// повторы не проверяются, но входят в общее число
+		checked   = len(dupOf)
+		pagesLeft = limits.MaxPages
+		// уже скачанные страницы: на одну страницу могут вести ссылки с разными якорями
+		crawled = make(map[string]bool)
+	)
+	// takePage - резервирует страницу из лимита обхода
+	takePage := func(page string) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		if pagesLeft <= 0 || crawled[page] {
+			return false
+		}
+		crawled[page] = true
+		pagesLeft--
+		return true
+	}
+
+	for depth := 0; len(level) > 0; depth++ {
+		found := make([][]foundLink, len(level))
+		follow := depth < limits.Depth
+		total := len(results)
+
+		c.runPool(len(level), func(k int) {
+			item := level[k]
+			checkOpts := opts
+			if depth == 0 {
+				checkOpts = linkOptions(opts, item.link)
+			} else {
+				checkOpts.Expect = nil
+			}
+
+			// ошибка уже записана в результат
+			res, _ := c.CheckLink(ctx, item.link.URL, checkOpts)
+			// каждый воркер пишет только в свою ячейку, остальные поля заполнены до запуска уровня
+			prev := results[item.idx]
+			res.Position, res.Source, res.FoundOn, res.Depth = prev.Position, prev.Source, prev.FoundOn, prev.Depth
+			results[item.idx] = res
+
+			mu.Lock()
+			checked++
+			if progress != nil {
+				progress(checked, total)
+			}
+			mu.Unlock()
+
+			if !follow || !item.page || !res.Status.OK() || res.FinalURL == "" {
+				return
+			}
+			page, err := url.Parse(res.FinalURL)
+			if err != nil || !inScope(item.root, page, limits.Scope) || !takePage(page.String()) {
+				return
+			}
+			found[k] = c.fetchPageLinks(ctx, page)
+		})
+
+		if ctx.Err() != nil {
+			break
+		}
+
+		// новые ссылки добавляются в порядке страниц и порядке на странице, чтобы отчёт был стабильным
+		next := make([]crawlItem, 0)
+		for k, item := range level {
+			pageURL := results[item.idx].URL
+			pageKey, _ := c.crawlKey(pageURL, opts)
+			for _, f := range found[k] {
+				key, _ := c.crawlKey(f.URL, opts)
+				// ссылки страницы на саму себя (#top и т.п.) не интересны
+				if key == pageKey {
+					continue
+				}
+				if j, ok := seen[key]; ok {
+					if !slices.Contains(results[j].FoundOn, pageURL) {
+						results[j].FoundOn = append(results[j].FoundOn, pageURL)
+					}
+					continue
+				}
+				if !c.crawlable(f.URL) {
+					continue
+				}
+
+				idx := len(results)
+				seen[key] = idx
+				results = append(results, models.CheckResult{
+					URL:      f.URL,
+					Position: idx + 1,
+					FoundOn:  []string{pageURL},
+					Depth:    depth + 1,
+				})
+				next = append(next, crawlItem{idx: idx, link: models.LinkSpec{URL: f.URL}, page: f.Page, root: item.root})
+			}
+		}
+		level = next
+	}
+
+	for i, j := range dupOf {
+		res := results[j]
+		res.URL = links[i].URL
+		res.DuplicateOf = links[j].URL
+		res.Position = i + 1
+		res.Source = links[i].Source
+		results[i] = res
+	}
+
+	return results
+}
+
+// crawlLimits - пределы обхода с подставленными значениями по умолчанию.
+func crawlLimits(o *models.CrawlOptions) models.CrawlOptions {
+	var limits models.CrawlOptions
+	if o != nil {
+		limits = *o
+	}
+	if limits.Depth <= 0 {
+		limits.Depth = models.DefaultCrawlDepth
+	}
+	if limits.MaxPages <= 0 {
+		limits.MaxPages = models.DefaultCrawlPages
+	}
+	if limits.Scope == "" {
+		limits.Scope = models.ScopeSameHost
+	}
+	return limits
+}
+
+// crawlKey - по какому ключу ссылки считаются одинаковыми при обходе, и разобранная каноническая форма.
+// Якорь входит в ключ: page#install и page#usage проверяются по отдельности.
+// Ссылки, которые не удалось нормализовать, сравниваются как есть.
+func (c *LinkChecker) crawlKey(link string, opts models.CheckOptions) (string, *url.URL) {
+	canonical, err := NormalizeURL(link, opts.SortQuery)
+	if err != nil {
+		return link, nil
+	}
+	u, _ := url.Parse(canonical)
+	if anchor := linkAnchor(link); anchor != "" {
+		return canonical + "#" + anchor, u
+	}
+	return canonical, u
+}
+
+// crawlable - найденную ссылку есть чем проверить. javascript:, tel:, data: и подобные пропускаются.
+func (c *LinkChecker) crawlable(link string) bool {
+	u, err := url.Parse(link)
+	if err != nil {
+		return true
+	}
+	_, ok := c.checkers[strings.ToLower(u.Scheme)]
+	return ok
+}
+
+// inScope - можно ли обходить страницу page, если обход начался с root.
+func inScope(root, page *url.URL, scope models.CrawlScope) bool {
+	if page.Scheme != "http" && page.Scheme != "https" {
+		return false
+	}
+	if root == nil {
+		return false
+	}
+	switch scope {
+	case models.ScopeAny:
+		return true
+	case models.ScopeSameDomain:
+		return sameSite(root, page)
+	}
+	return strings.EqualFold(root.Hostname(), page.Hostname())
+}
+
+// fetchPageLinks - скачивает HTML-страницу и достаёт из неё ссылки.
+// Страница уже проверена, поэтому ошибки здесь не важны: без HTML просто нечего обходить.
+func (c *LinkChecker) fetchPageLinks(ctx context.Context, page *url.URL) []foundLink {
+	var t models.Timings
+	resp, err := c.send(ctx, http.MethodGet, page, &t, maxPageBytes)
+	if err != nil || resp.StatusCode/100 != 2 {
+		return nil
+	}
+	mt, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || (mt != "text/html" && mt != "application/xhtml+xml") {
+		return nil
+	}
+	return extractHTMLLinks(bytes.NewReader(resp.Body), page)
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// crawlSite - небольшой сайт для обхода и второй хост, на который он ссылается.
+func crawlSite(t *testing.T) (site, other *httptest.Server) {
+	t.Helper()
+
+	other = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<a href="/other-inner">inner</a>`))
+	}))
+	// тот же адрес, но другое имя хоста
+	otherURL := strings.Replace(other.URL, "127.0.0.1", "localhost", 1)
+
+	pages := map[string]string{
+		"/": `<html><head><script src="/app.js"></script></head><body>
+			<a href="#top">top</a>
+			<a href="/about">about</a>
+			<a href="/missing">missing</a>
+			<a href="javascript:void(0)">js</a>
+			<img src="/logo.png">
+			<a href="` + otherURL + `/page">partner</a>
+		</body></html>`,
+		"/about": `<a href="/">home</a><a href="/deep">deep</a><link rel="stylesheet" href="/style.css">`,
+		"/deep":  `<a href="/deeper">deeper</a>`,
+	}
+	site = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if body, ok := pages[r.URL.Path]; ok {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte(body))
+			return
+		}
+		switch r.URL.Path {
+		case "/logo.png", "/app.js", "/style.css", "/deeper":
+			w.WriteHeader(http.StatusOK)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	return site, other
+}
+
+// resultURLs - ссылки из результатов по порядку.
+func resultURLs(results []models.CheckResult) []string {
+	urls := make([]string, len(results))
+	for i, r := range results {
+		urls[i] = r.URL
+	}
+	return urls
+}
+
+func TestCrawl(t *testing.T) {
+	site, other := crawlSite(t)
+	defer site.Close()
+	defer other.Close()
+	otherURL := strings.Replace(other.URL, "127.0.0.1", "localhost", 1)
+
+	var checked, total int
+	results := newTestChecker().Crawl(context.Background(), models.LinkSpecs(site.URL), models.CheckOptions{
+		Crawl: &models.CrawlOptions{},
+	}, func(c, n int) { checked, total = c, n })
+
+	assert.Equal(t, []string{
+		site.URL,
+		site.URL + "/app.js",
+		site.URL + "/about",
+		site.URL + "/missing",
+		site.URL + "/logo.png",
+		otherURL + "/page",
+		site.URL + "/deep",
+		site.URL + "/style.css",
+	}, resultURLs(results))
+	assert.Equal(t, len(results), checked)
+	assert.Equal(t, len(results), total)
+
+	for i, res := range results {
+		assert.Equal(t, i+1, res.Position)
+	}
+
+	// присланная ссылка встретилась на странице about
+	assert.Equal(t, []string{site.URL + "/about"}, results[0].FoundOn)
+	assert.Equal(t, 0, results[0].Depth)
+
+	missing := results[3]
+	assert.Equal(t, models.StatusClientError, missing.Status)
+	assert.Equal(t, []string{site.URL}, missing.FoundOn)
+	assert.Equal(t, 1, missing.Depth)
+
+	// /deep на втором уровне проверена, но не обходится; чужой хост тоже не обходится
+	assert.Equal(t, 2, results[6].Depth)
+	assert.Equal(t, models.StatusAvailable, results[5].Status)
+}
+
+func TestCrawl_Limits(t *testing.T) {
+	site, other := crawlSite(t)
+	defer site.Close()
+	defer other.Close()
+
+	tests := []struct {
+		name      string
+		crawl     models.CrawlOptions
+		wantIn    []string
+		wantNotIn []string
+	}{
+		{name: "depth 1", crawl: models.CrawlOptions{Depth: 1}, wantIn: []string{"/about"}, wantNotIn: []string{"/deep"}},
+		{name: "one page", crawl: models.CrawlOptions{MaxPages: 1}, wantIn: []string{"/about"}, wantNotIn: []string{"/deep"}},
+		{name: "deeper", crawl: models.CrawlOptions{Depth: 3}, wantIn: []string{"/deeper"}, wantNotIn: []string{"/other-inner"}},
+		{name: "any scope", crawl: models.CrawlOptions{Scope: models.ScopeAny}, wantIn: []string{"/other-inner"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			crawl := tt.crawl
+			results := newTestChecker().Crawl(context.Background(), models.LinkSpecs(site.URL), models.CheckOptions{Crawl: &crawl}, nil)
+
+			paths := make([]string, len(results))
+			for i, r := range results {
+				u, err := url.Parse(r.URL)
+				require.NoError(t, err)
+				paths[i] = u.Path
+			}
+			for _, p := range tt.wantIn {
+				assert.Contains(t, paths, p)
+			}
+			for _, p := range tt.wantNotIn {
+				assert.NotContains(t, paths, p)
+			}
+		})
+	}
+}
+
+func TestCrawl_DuplicateSeeds(t *testing.T) {
+	site, other := crawlSite(t)
+	defer site.Close()
+	defer other.Close()
+
+	results := newTestChecker().Crawl(context.Background(), models.LinkSpecs(site.URL+"/about", site.URL+"/about#top"),
+		models.CheckOptions{Crawl: &models.CrawlOptions{Depth: 1}}, nil)
+
+	require.GreaterOrEqual(t, len(results), 2)
+	assert.Equal(t, site.URL+"/about#top", results[1].URL)
+	assert.Equal(t, site.URL+"/about", results[1].DuplicateOf)
+	assert.Equal(t, 2, results[1].Position)
+}
+
+func TestExtractHTMLLinks(t *testing.T) {
+	base, _ := url.Parse("https://example.com/docs/")
+	body := `<html><head><base href="/v2/"><link rel="icon" href="favicon.ico"></head>
+		<body><a href="guide">guide</a><a href="">empty</a><img src="//cdn.example.com/a.png"><script>var x = "<a href='nope'>";</script></body></html>`
+
+	links := extractHTMLLinks(strings.NewReader(body), base)
+	assert.Equal(t, []foundLink{
+		{URL: "https://example.com/v2/favicon.ico"},
+		{URL: "https://example.com/v2/guide", Page: true},
+		{URL: "https://cdn.example.com/a.png"},
+	}, links)
+}
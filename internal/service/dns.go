@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/models"
+)
+
+// Resolver - DNS-запросы, нужные для ссылок dns: и mailto:. Его реализует *net.Resolver.
+type Resolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+	LookupIP(ctx context.Context, network, host string) ([]net.IP, error)
+	LookupMX(ctx context.Context, name string) ([]*net.MX, error)
+	LookupCNAME(ctx context.Context, host string) (string, error)
+}
+
+// dnsTypes - типы записей, которые понимает ссылка dns:name?type=...
+var dnsTypes = []string{"A", "AAAA", "MX", "CNAME"}
+
+// errNoRecords - имя есть, но записей нужного типа нет.
+var errNoRecords = errors.New("no records")
+
+// checkDNS - ссылка dns:example.com?type=MX рабочая, если у имени есть записи нужного типа.
+// Без type проверяется, что имя резолвится хоть в какой-то адрес. Найденные записи попадают в Records.
+func (c *LinkChecker) checkDNS(ctx context.Context, u *url.URL, _ models.CheckOptions) (models.CheckResult, error) {
+	name := u.Opaque
+	rtype := strings.ToUpper(u.Query().Get("type"))
+
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.RequestTimeout)
+	defer cancel()
+
+	var (
+		records []string
+		err     error
+	)
+	switch rtype {
+	case "":
+		records, err = c.cfg.Resolver.LookupHost(ctx, name)
+	case "A", "AAAA":
+		network := "ip4"
+		if rtype == "AAAA" {
+			network = "ip6"
+		}
+		var ips []net.IP
+		ips, err = c.cfg.Resolver.LookupIP(ctx, network, name)
+		for _, ip := range ips {
+			records = append(records, ip.String())
+		}
+	case "MX":
+		var mxs []*net.MX
+		mxs, err = c.cfg.Resolver.LookupMX(ctx, name)
+		for _, mx := range mxs {
+			records = append(records, fmt.Sprintf("%s %d", strings.TrimSuffix(mx.Host, "."), mx.Pref))
+		}
+	case "CNAME":
+		var cname string
+		cname, err = c.cfg.Resolver.LookupCNAME(ctx, name)
+		// у имени без CNAME резолвер возвращает само имя
+		if err == nil && !strings.EqualFold(strings.TrimSuffix(cname, "."), name) {
+			records = append(records, strings.TrimSuffix(cname, "."))
+		}
+	default:
+		err = fmt.Errorf("unsupported dns record type %q, use one of %v", rtype, dnsTypes)
+		return models.CheckResult{Status: models.StatusInvalidURL, Error: err.Error()}, err
+	}
+	if err == nil && len(records) == 0 {
+		err = fmt.Errorf("%w of type %s for %s", errNoRecords, rtype, name)
+	}
+
+	res := models.CheckResult{Records: records}
+	if err != nil {
+		res.Status = dnsStatus(err)
+		res.Error = err.Error()
+		return res, err
+	}
+	res.Status = models.StatusAvailable
+	return res, nil
+}
+
+// checkMailto - у домена каждого адреса из mailto: должны быть MX-записи.
+// Null MX (RFC 7505, запись ".") означает, что домен почту не принимает.
+func (c *LinkChecker) checkMailto(ctx context.Context, u *url.URL, _ models.CheckOptions) (models.CheckResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.RequestTimeout)
+	defer cancel()
+
+	var res models.CheckResult
+	fail := func(err error) (models.CheckResult, error) {
+		res.Status = dnsStatus(err)
+		res.Error = err.Error()
+		return res, err
+	}
+
+	domains := make([]string, 0, 1)
+	for _, addr := range strings.Split(u.Opaque, ",") {
+		_, domain, ok := strings.Cut(addr, "@")
+		if !ok || domain == "" {
+			err := fmt.Errorf("invalid mailto address %q", addr)
+			res.Status = models.StatusInvalidURL
+			res.Error = err.Error()
+			return res, err
+		}
+		if !slices.Contains(domains, domain) {
+			domains = append(domains, domain)
+		}
+	}
+
+	for _, domain := range domains {
+		mxs, err := c.cfg.Resolver.LookupMX(ctx, domain)
+		if err != nil {
+			return fail(err)
+		}
+		if len(mxs) == 0 {
+			return fail(fmt.Errorf("%w of type MX for %s", errNoRecords, domain))
+		}
+		if len(mxs) == 1 && mxs[0].Host == "." {
+			return fail(fmt.Errorf("%s does not accept mail (null MX)", domain))
+		}
+		for _, mx := range mxs {
+			res.Records = append(res.Records, fmt.Sprintf("%s %s %d", domain, strings.TrimSuffix(mx.Host, "."), mx.Pref))
+		}
+	}
+	res.Status = models.StatusAvailable
+	return res, nil
+}
+
+// dnsStatus - отсутствие записей тоже считается ошибкой DNS.
+func dnsStatus(err error) models.LinkStatus {
+	if errors.Is(err, errNoRecords) {
+		return models.StatusDNSFailure
+	}
+	status := classifyError(err)
+	if status == models.StatusUnreachable {
+		return models.StatusDNSFailure
+	}
+	return status
+}
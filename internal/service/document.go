@@ -0,0 +1,252 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/models"
+	"golang.org/x/net/html"
+	"golang.org/x/net/idna"
+	"golang.org/x/net/publicsuffix"
+)
+
+// Типы документов, из которых достаются ссылки.
+const (
+	DocumentText     = "text/plain"
+	DocumentMarkdown = "text/markdown"
+	DocumentHTML     = "text/html"
+)
+
+// maxDocumentLinks - сколько ссылок берётся из одного загруженного документа.
+const maxDocumentLinks = 50000
+
+var (
+	// ErrDocumentType - документ такого типа не разбирается.
+	ErrDocumentType = errors.New("unsupported document type")
+	// ErrDocumentLinks - в документе больше maxDocumentLinks ссылок.
+	ErrDocumentLinks = fmt.Errorf("document has more than %d links", maxDocumentLinks)
+)
+
+// textURLPattern - ссылки в тексте: со схемой или голый хост вида example.com/path.
+// Хвостовая пунктуация и лишние скобки отрезаются уже после поиска (trimURL).
+var textURLPattern = regexp.MustCompile(
+	"(?i:(?:https?|wss?|ftp)://|mailto:)[^\\s<>\"'`]+" +
+		"|(?:[\\p{L}\\p{N}](?:[\\p{L}\\p{N}-]*[\\p{L}\\p{N}])?\\.)+\\p{L}{2,}(?::\\d+)?(?:/[^\\s<>\"'`]*)?")
+
+// documentSchemes - схемы, ссылки с которыми берутся из атрибутов HTML. Остальное (javascript:, tel:) не ссылки на проверку.
+var documentSchemes = map[string]bool{"http": true, "https": true, "ws": true, "wss": true, "ftp": true, "mailto": true}
+
+// fileTLDs - домены верхнего уровня, совпадающие с расширениями файлов. Голый "readme.md" - скорее файл,
+// поэтому такие хосты берутся, только если у них есть путь или www.
+var fileTLDs = map[string]bool{"md": true, "py": true, "sh": true, "zip": true, "mov": true}
+
+// DocumentType - тип документа по Content-Type, а если он не задан или общий - по расширению файла.
+func DocumentType(contentType, filename string) (string, error) {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil || mt == "application/octet-stream" {
+		mt = ""
+	}
+	switch mt {
+	case DocumentText, DocumentHTML, DocumentMarkdown:
+		return mt, nil
+	case "text/x-markdown":
+		return DocumentMarkdown, nil
+	case "application/xhtml+xml":
+		return DocumentHTML, nil
+	case "":
+		switch strings.ToLower(path.Ext(filename)) {
+		case ".txt":
+			return DocumentText, nil
+		case ".md", ".markdown":
+			return DocumentMarkdown, nil
+		case ".html", ".htm":
+			return DocumentHTML, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %q", ErrDocumentType, contentType)
+}
+
+// ExtractDocument - ссылки из документа в порядке появления. Повторы не убираются: у каждого своё место в Source.
+// Source - строка ("line 12" или "docs.md:12", если известно имя файла), для HTML ещё и элемент.
+// Markdown разбирается как текст: и [текст](url), и <url>, и голые ссылки находятся одним поиском.
+func ExtractDocument(r io.Reader, docType, name string) ([]models.LinkSpec, error) {
+	switch docType {
+	case DocumentText, DocumentMarkdown:
+		return extractTextLinks(r, name)
+	case DocumentHTML:
+		return extractDocumentHTML(r, name)
+	}
+	return nil, fmt.Errorf("%w: %q", ErrDocumentType, docType)
+}
+
+// documentLines - собирает ссылки документа с номером строки.
+type documentLines struct {
+	name  string
+	links []models.LinkSpec
+}
+
+func (d *documentLines) add(link string, line int, elem string) error {
+	if len(d.links) >= maxDocumentLinks {
+		return ErrDocumentLinks
+	}
+	source := fmt.Sprintf("line %d", line)
+	if d.name != "" {
+		source = fmt.Sprintf("%s:%d", d.name, line)
+	}
+	if elem != "" {
+		source += " " + elem
+	}
+	d.links = append(d.links, models.LinkSpec{URL: link, Source: source})
+	return nil
+}
+
+// scan - ссылки из куска текста, который начинается на строке line.
+func (d *documentLines) scan(text string, line int, elem string) error {
+	last := 0
+	for _, m := range textURLPattern.FindAllStringIndex(text, -1) {
+		line += strings.Count(text[last:m[0]], "\n")
+		last = m[0]
+
+		link := trimURL(text[m[0]:m[1]])
+		prev, _ := utf8.DecodeLastRuneInString(text[:m[0]])
+		if !urlStart(link, prev) {
+			continue
+		}
+		if err := d.add(link, line, elem); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extractTextLinks - ссылки из простого текста и Markdown.
+func extractTextLinks(r io.Reader, name string) ([]models.LinkSpec, error) {
+	d := &documentLines{name: name}
+	br := bufio.NewReader(r)
+	for line := 1; ; line++ {
+		text, err := br.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if scanErr := d.scan(text, line, ""); scanErr != nil {
+			return nil, scanErr
+		}
+		if err == io.EOF {
+			return d.links, nil
+		}
+	}
+}
+
+// extractDocumentHTML - ссылки из атрибутов (как при обходе, см. linkAttrs) и из текста страницы.
+// Относительные ссылки пропускаются: адреса страницы нет, разрешать их не от чего.
+// Текст внутри <a> не просматривается, там обычно та же ссылка, что и в href; script и style - тоже.
+func extractDocumentHTML(r io.Reader, name string) ([]models.LinkSpec, error) {
+	d := &documentLines{name: name}
+	line := 1
+	// вложенность a, script и style
+	skipText := 0
+
+	z := html.NewTokenizer(r)
+	for {
+		tt := z.Next()
+		tokLine := line
+		line += bytes.Count(z.Raw(), []byte("\n"))
+
+		switch tt {
+		case html.ErrorToken:
+			if err := z.Err(); err != io.EOF {
+				return nil, err
+			}
+			return d.links, nil
+		case html.TextToken:
+			if skipText == 0 {
+				if err := d.scan(string(z.Text()), tokLine, ""); err != nil {
+					return nil, err
+				}
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			if tt == html.StartTagToken && (tok.Data == "a" || tok.Data == "script" || tok.Data == "style") {
+				skipText++
+			}
+			attrName, ok := linkAttrs[tok.Data]
+			if !ok {
+				continue
+			}
+			ref := strings.TrimSpace(attr(tok, attrName))
+			u, err := url.Parse(ref)
+			if ref == "" || (err == nil && !documentSchemes[strings.ToLower(u.Scheme)]) {
+				continue
+			}
+			if err := d.add(ref, tokLine, fmt.Sprintf("<%s %s>", tok.Data, attrName)); err != nil {
+				return nil, err
+			}
+		case html.EndTagToken:
+			tok := z.Token()
+			if (tok.Data == "a" || tok.Data == "script" || tok.Data == "style") && skipText > 0 {
+				skipText--
+			}
+		}
+	}
+}
+
+// trimURL - отрезает пунктуацию, которая обычно завершает предложение, а не ссылку,
+// и закрывающие скобки без пары внутри ссылки: "(см. https://example.com/a)".
+func trimURL(link string) string {
+	for link != "" {
+		last := link[len(link)-1]
+		switch {
+		case strings.IndexByte(".,;:!?*_~", last) >= 0:
+		case last == ')' && strings.Count(link, ")") > strings.Count(link, "("):
+		case last == ']' && strings.Count(link, "]") > strings.Count(link, "["):
+		case last == '}' && strings.Count(link, "}") > strings.Count(link, "{"):
+		default:
+			return link
+		}
+		link = link[:len(link)-1]
+	}
+	return link
+}
+
+// urlStart - найденное действительно начало ссылки. prev - символ перед ней.
+// Голый хост не должен быть частью почтового адреса, пути или слова и должен оканчиваться на настоящий домен верхнего уровня.
+func urlStart(link string, prev rune) bool {
+	if unicode.IsLetter(prev) || unicode.IsDigit(prev) {
+		return false
+	}
+	if i := strings.Index(link, ":"); i > 0 && (strings.HasPrefix(link[i:], "://") || strings.EqualFold(link[:i], "mailto")) {
+		return true
+	}
+	if strings.ContainsRune("@./-_:", prev) {
+		return false
+	}
+
+	host, rest, hasPath := strings.Cut(link, "/")
+	host, _, _ = strings.Cut(host, ":")
+	ascii, err := idna.Lookup.ToASCII(strings.ToLower(host))
+	if err != nil {
+		return false
+	}
+	// сам хост не должен быть публичным суффиксом (co.uk), а домен верхнего уровня - выдуманным (file.txt)
+	tld := ascii[strings.LastIndex(ascii, ".")+1:]
+	if _, icann := publicsuffix.PublicSuffix(tld); !icann {
+		return false
+	}
+	if _, err := publicsuffix.EffectiveTLDPlusOne(ascii); err != nil {
+		return false
+	}
+	if fileTLDs[tld] && !(hasPath && rest != "") && !strings.HasPrefix(ascii, "www.") {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractDocument_Text(t *testing.T) {
+	doc := `See https://example.com/a). Details: (https://en.wikipedia.org/wiki/Go_(language)).
+Write to user@example.com or mailto:team@example.org, not to file.txt or readme.md.
+Docs: [guide](docs.example.net/guide), <https://example.io>, пример.рф and www.site.md
+version 1.2.3, e.g. nothing here; https://example.com:8443/x?q=1#frag
+`
+	links, err := ExtractDocument(strings.NewReader(doc), DocumentMarkdown, "")
+	require.NoError(t, err)
+	assert.Equal(t, []models.LinkSpec{
+		{URL: "https://example.com/a", Source: "line 1"},
+		{URL: "https://en.wikipedia.org/wiki/Go_(language)", Source: "line 1"},
+		{URL: "mailto:team@example.org", Source: "line 2"},
+		{URL: "docs.example.net/guide", Source: "line 3"},
+		{URL: "https://example.io", Source: "line 3"},
+		{URL: "пример.рф", Source: "line 3"},
+		{URL: "www.site.md", Source: "line 3"},
+		{URL: "https://example.com:8443/x?q=1#frag", Source: "line 4"},
+	}, links)
+}
+
+func TestExtractDocument_HTML(t *testing.T) {
+	doc := `<html>
+<body>
+<a href="https://example.com/docs">https://example.com/docs</a>
+<img src="/relative.png"><a href="javascript:void(0)">js</a>
+<p>Mirror:
+example.org/files</p>
+<script>var u = "https://tracker.example.com";</script>
+<link href="https://cdn.example.com/site.css">
+</body></html>`
+
+	links, err := ExtractDocument(strings.NewReader(doc), DocumentHTML, "index.html")
+	require.NoError(t, err)
+	assert.Equal(t, []models.LinkSpec{
+		{URL: "https://example.com/docs", Source: "index.html:3 <a href>"},
+		{URL: "example.org/files", Source: "index.html:6"},
+		{URL: "https://cdn.example.com/site.css", Source: "index.html:8 <link href>"},
+	}, links)
+}
+
+func TestDocumentType(t *testing.T) {
+	tests := []struct {
+		contentType, filename, want string
+	}{
+		{contentType: "text/plain; charset=utf-8", want: DocumentText},
+		{contentType: "text/x-markdown", want: DocumentMarkdown},
+		{contentType: "application/octet-stream", filename: "README.md", want: DocumentMarkdown},
+		{filename: "page.HTM", want: DocumentHTML},
+	}
+	for _, tt := range tests {
+		got, err := DocumentType(tt.contentType, tt.filename)
+		require.NoError(t, err, tt)
+		assert.Equal(t, tt.want, got, tt)
+	}
+
+	_, err := DocumentType("application/pdf", "doc.pdf")
+	assert.ErrorIs(t, err, ErrDocumentType)
+}
+
+func TestCheckAll_KeepsSource(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	links := []models.LinkSpec{{URL: srv.URL, Source: "docs.md:3"}, {URL: srv.URL + "/", Source: "docs.md:10"}}
+	results := newTestChecker().CheckAll(context.Background(), links, models.CheckOptions{})
+	require.Len(t, results, 2)
+
+	assert.Equal(t, "docs.md:3", results[0].Source)
+	// у повтора своё место в документе
+	assert.Equal(t, srv.URL, results[1].DuplicateOf)
+	assert.Equal(t, "docs.md:10", results[1].Source)
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/models"
+)
+
+// defaultBodyLimit - сколько тела читается для проверок, если max_bytes не задан.
+const defaultBodyLimit = 1 << 20
+
+// applyExpectations - сверяет последний ответ с ожиданиями и меняет статус результата.
+// Код из списка допустимых делает ссылку рабочей; невыполненные ожидания дают assertion_failed с причинами.
+func applyExpectations(res *models.CheckResult, e *models.Expectations, resp *response) {
+	var failures []string
+
+	if len(e.Status) > 0 {
+		if !slices.Contains(e.Status, resp.StatusCode) {
+			failures = append(failures, fmt.Sprintf("status %d not in %v", resp.StatusCode, e.Status))
+		} else if !res.Status.OK() {
+			res.Status = classifyResponse(200, len(res.Redirects) > 0)
+		}
+	} else if !res.Status.OK() {
+		// ошибочный ответ и так нерабочий, тело проверять незачем
+		return
+	}
+
+	failures = append(failures, checkBody(e, resp)...)
+	if len(failures) > 0 {
+		res.Status = models.StatusAssertionFailed
+		res.AssertionFailures = failures
+	}
+}
+
+// checkBody - проверки размера и содержимого тела.
+func checkBody(e *models.Expectations, resp *response) []string {
+	var failures []string
+
+	if e.MaxBytes > 0 && (resp.Truncated || resp.ContentLength > e.MaxBytes) {
+		failures = append(failures, fmt.Sprintf("body larger than %d bytes", e.MaxBytes))
+	}
+
+	body := resp.Body
+	if e.BodyContains != "" && !bytes.Contains(body, []byte(e.BodyContains)) {
+		failures = append(failures, fmt.Sprintf("body does not contain %q", e.BodyContains))
+	}
+	if e.BodyNotContains != "" && bytes.Contains(body, []byte(e.BodyNotContains)) {
+		failures = append(failures, fmt.Sprintf("body contains %q", e.BodyNotContains))
+	}
+	if e.BodyMatches != "" {
+		re, err := regexp.Compile(e.BodyMatches)
+		switch {
+		case err != nil:
+			failures = append(failures, fmt.Sprintf("invalid body_matches: %v", err))
+		case !re.Match(body):
+			failures = append(failures, fmt.Sprintf("body does not match %q", e.BodyMatches))
+		}
+	}
+	if e.BodyNotMatches != "" {
+		re, err := regexp.Compile(e.BodyNotMatches)
+		switch {
+		case err != nil:
+			failures = append(failures, fmt.Sprintf("invalid body_not_matches: %v", err))
+		case re.Match(body):
+			failures = append(failures, fmt.Sprintf("body matches %q", e.BodyNotMatches))
+		}
+	}
+	if e.JSONPath != "" {
+		if msg := checkJSONPath(body, e.JSONPath, e.JSONEquals); msg != "" {
+			failures = append(failures, msg)
+		}
+	}
+	return failures
+}
+
+// checkJSONPath - значение по пути должно совпасть с ожидаемым. Пустая строка - совпало.
+func checkJSONPath(body []byte, path string, want json.RawMessage) string {
+	var doc any
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return "body is not valid JSON"
+	}
+	got, ok := jsonLookup(doc, path)
+	if !ok {
+		return fmt.Sprintf("json_path %s not found", path)
+	}
+
+	var expected any
+	if err := json.Unmarshal(want, &expected); err != nil {
+		return "invalid json_equals"
+	}
+	if !reflect.DeepEqual(got, expected) {
+		actual, _ := json.Marshal(got)
+		return fmt.Sprintf("json_path %s = %s, want %s", path, actual, want)
+	}
+	return ""
+}
+
+// jsonLookup - значение по пути вида "$.data.items[0].status" (префикс "$." необязателен).
+func jsonLookup(doc any, path string) (any, bool) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if path == "" {
+		return doc, true
+	}
+
+	cur := doc
+	for _, seg := range strings.Split(path, ".") {
+		name, rest, _ := strings.Cut(seg, "[")
+		if name != "" {
+			obj, ok := cur.(map[string]any)
+			if !ok {
+				return nil, false
+			}
+			if cur, ok = obj[name]; !ok {
+				return nil, false
+			}
+		}
+
+		// индексы массивов: [0][1]
+		for rest != "" {
+			idx, after, ok := strings.Cut(rest, "]")
+			if !ok {
+				return nil, false
+			}
+			n, err := strconv.Atoi(idx)
+			arr, isArr := cur.([]any)
+			if err != nil || !isArr || n < 0 || n >= len(arr) {
+				return nil, false
+			}
+			cur = arr[n]
+			rest = strings.TrimPrefix(after, "[")
+		}
+	}
+	return cur, true
+}
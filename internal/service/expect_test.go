@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// expectServer - отдаёт разные тела по путям и запоминает методы запросов.
+func expectServer(methods *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*methods = append(*methods, r.Method)
+		switch r.URL.Path {
+		case "/maintenance":
+			w.Write([]byte("<h1>Site is under maintenance</h1>"))
+		case "/api":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"status":"up","data":{"items":[{"id":7}]}}`))
+		case "/big":
+			w.Write([]byte(strings.Repeat("a", 4096)))
+		case "/gone":
+			http.Error(w, "gone", http.StatusNotFound)
+		default:
+			w.Write([]byte("version v1.2.3"))
+		}
+	}))
+}
+
+func TestCheckLink_Expectations(t *testing.T) {
+	var methods []string
+	srv := expectServer(&methods)
+	defer srv.Close()
+
+	tests := []struct {
+		name         string
+		path         string
+		expect       models.Expectations
+		wantStatus   models.LinkStatus
+		wantFailures int
+	}{
+		{name: "maintenance page", path: "/maintenance", expect: models.Expectations{BodyNotContains: "maintenance"}, wantStatus: models.StatusAssertionFailed, wantFailures: 1},
+		{name: "regex", path: "/", expect: models.Expectations{BodyMatches: `v\d+\.\d+`}, wantStatus: models.StatusAvailable},
+		{name: "regex mismatch", path: "/", expect: models.Expectations{BodyMatches: `v2\.`}, wantStatus: models.StatusAssertionFailed, wantFailures: 1},
+		{name: "json path", path: "/api", expect: models.Expectations{JSONPath: "$.data.items[0].id", JSONEquals: json.RawMessage(`7`)}, wantStatus: models.StatusAvailable},
+		{name: "json path mismatch", path: "/api", expect: models.Expectations{JSONPath: "status", JSONEquals: json.RawMessage(`"down"`)}, wantStatus: models.StatusAssertionFailed, wantFailures: 1},
+		{name: "json path missing", path: "/api", expect: models.Expectations{JSONPath: "data.items[3]", JSONEquals: json.RawMessage(`1`)}, wantStatus: models.StatusAssertionFailed, wantFailures: 1},
+		{name: "not json", path: "/", expect: models.Expectations{JSONPath: "status", JSONEquals: json.RawMessage(`"up"`)}, wantStatus: models.StatusAssertionFailed, wantFailures: 1},
+		{name: "too big", path: "/big", expect: models.Expectations{MaxBytes: 1024}, wantStatus: models.StatusAssertionFailed, wantFailures: 1},
+		{name: "expected 404", path: "/gone", expect: models.Expectations{Status: []int{404}}, wantStatus: models.StatusAvailable},
+		{name: "unexpected 200", path: "/", expect: models.Expectations{Status: []int{404}, BodyContains: "nope"}, wantStatus: models.StatusAssertionFailed, wantFailures: 2},
+		{name: "error page without status list", path: "/gone", expect: models.Expectations{BodyContains: "nope"}, wantStatus: models.StatusClientError},
+	}
+
+	checker := newTestChecker()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			methods = nil
+			expect := tt.expect
+			res, err := checker.CheckLink(context.Background(), srv.URL+tt.path, models.CheckOptions{Expect: &expect})
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantStatus, res.Status)
+			assert.Len(t, res.AssertionFailures, tt.wantFailures)
+			if tt.wantFailures > 0 {
+				assert.Equal(t, strings.Join(res.AssertionFailures, "; "), res.Error)
+			}
+			// тело нужно проверять, поэтому сразу GET
+			assert.Equal(t, []string{http.MethodGet}, methods)
+			assert.Equal(t, http.MethodGet, res.Method)
+		})
+	}
+}
+
+func TestCheckAll_LinkExpectOverridesRequest(t *testing.T) {
+	var methods []string
+	srv := expectServer(&methods)
+	defer srv.Close()
+
+	links := []models.LinkSpec{
+		{URL: srv.URL + "/maintenance"},
+		{URL: srv.URL + "/maintenance", Expect: &models.Expectations{BodyContains: "maintenance"}},
+	}
+	opts := models.CheckOptions{Expect: &models.Expectations{BodyNotContains: "maintenance"}}
+
+	res := newTestChecker().CheckAll(context.Background(), links, opts)
+	require.Len(t, res, 2)
+
+	assert.Equal(t, models.StatusAssertionFailed, res[0].Status)
+	// у второй ссылки свои ожидания, это отдельная проверка, а не дубликат
+	assert.Equal(t, models.StatusAvailable, res[1].Status)
+	assert.Empty(t, res[1].DuplicateOf)
+}
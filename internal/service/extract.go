@@ -0,0 +1,100 @@
+package service
+
+import (
+	"io"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// foundLink - ссылка, найденная в документе.
+type foundLink struct {
+	// URL - абсолютная ссылка.
+	URL string
+	// Page - ссылка из a[href], то есть на другую страницу, а не на ресурс.
+	Page bool
+}
+
+// linkAttrs - какие атрибуты каких тегов содержат ссылки.
+var linkAttrs = map[string]string{
+	"a":      "href",
+	"img":    "src",
+	"script": "src",
+	"link":   "href",
+}
+
+// extractHTMLLinks - ссылки из a[href], img[src], script[src] и link[href] в порядке появления.
+// Относительные ссылки разрешаются от base или от <base href>, если он есть. Пустые ссылки пропускаются.
+func extractHTMLLinks(r io.Reader, base *url.URL) []foundLink {
+	var links []foundLink
+	baseSet := false
+
+	z := html.NewTokenizer(r)
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			// io.EOF или битый HTML - отдаём то, что успели найти
+			return links
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			if tok.Data == "base" && !baseSet {
+				if href := attr(tok, "href"); href != "" {
+					if u, err := base.Parse(href); err == nil {
+						base, baseSet = u, true
+					}
+				}
+				continue
+			}
+
+			name, ok := linkAttrs[tok.Data]
+			if !ok {
+				continue
+			}
+			ref := strings.TrimSpace(attr(tok, name))
+			if ref == "" {
+				continue
+			}
+			u, err := base.Parse(ref)
+			if err != nil {
+				// битая ссылка тоже результат: проверка покажет invalid_url
+				links = append(links, foundLink{URL: ref, Page: tok.Data == "a"})
+				continue
+			}
+			links = append(links, foundLink{URL: u.String(), Page: tok.Data == "a"})
+		}
+	}
+}
+
+// attr - значение атрибута тега.
+func attr(tok html.Token, name string) string {
+	for _, a := range tok.Attr {
+		if a.Key == name {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// extractAnchors - все якоря документа: атрибуты id любых элементов и name у a.
+func extractAnchors(r io.Reader) map[string]bool {
+	ids := make(map[string]bool)
+
+	z := html.NewTokenizer(r)
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return ids
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			if id := attr(tok, "id"); id != "" {
+				ids[id] = true
+			}
+			if tok.Data == "a" {
+				if name := attr(tok, "name"); name != "" {
+					ids[name] = true
+				}
+			}
+		}
+	}
+}
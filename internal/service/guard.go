@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrBlocked - адрес запрещён сетевой политикой сервиса.
+var ErrBlocked = errors.New("blocked by network policy")
+
+// blockedNetworks - внутренние и служебные сети, куда проверка не ходит без явного разрешения.
+var blockedNetworks = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("10.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("127.0.0.0/8"),
+	netip.MustParsePrefix("169.254.0.0/16"), // в том числе метаданные облака 169.254.169.254
+	netip.MustParsePrefix("172.16.0.0/12"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.168.0.0/16"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("224.0.0.0/4"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("::/128"),
+	netip.MustParsePrefix("::1/128"),
+	// NAT64 и 6to4 прячут внутри любой IPv4, в том числе внутренний
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("2002::/16"),
+	netip.MustParsePrefix("fc00::/7"),
+	netip.MustParsePrefix("fe80::/10"),
+	netip.MustParsePrefix("ff00::/8"),
+}
+
+// Allowlist - сети и хосты, которым разрешено быть внутренними.
+// Хост example.com разрешает и свои поддомены.
+type Allowlist struct {
+	Networks []netip.Prefix
+	Hosts    []string
+}
+
+// allowsHost - хост явно разрешён.
+func (a Allowlist) allowsHost(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, h := range a.Hosts {
+		h = strings.ToLower(strings.TrimSuffix(h, "."))
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsIP - к адресу можно подключаться.
+func (a Allowlist) allowsIP(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	for _, p := range a.Networks {
+		if p.Contains(ip) {
+			return true
+		}
+	}
+	for _, p := range blockedNetworks {
+		if p.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// guardedDialer - возвращает DialContext, который проверяет адрес уже после резолва.
+// Проверка идёт на каждое соединение, поэтому покрывает и все хопы редиректов, и DNS rebinding.
+func guardedDialer(allow Allowlist, timeout time.Duration) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	plain := &net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second}
+	guarded := &net.Dialer{
+		Timeout:   timeout,
+		KeepAlive: 30 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			ap, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return fmt.Errorf("%w: unexpected address %s", ErrBlocked, address)
+			}
+			if !allow.allowsIP(ap.Addr()) {
+				return fmt.Errorf("%w: %s is a private or reserved address", ErrBlocked, ap.Addr().Unmap())
+			}
+			return nil
+		},
+	}
+
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(addr)
+		if err == nil && allow.allowsHost(host) {
+			return plain.DialContext(ctx, network, addr)
+		}
+		return guarded.DialContext(ctx, network, addr)
+	}
+}
+
+// ParseAllowlist - разбирает список вида "10.1.0.0/16,192.168.1.5,intra.local".
+// Одиночный IP превращается в сеть из одного адреса, всё остальное считается хостом.
+func ParseAllowlist(s string) (Allowlist, error) {
+	var a Allowlist
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if strings.Contains(part, "/") {
+			p, err := netip.ParsePrefix(part)
+			if err != nil {
+				return Allowlist{}, fmt.Errorf("allowlist %q: %w", part, err)
+			}
+			a.Networks = append(a.Networks, p.Masked())
+			continue
+		}
+		if ip, err := netip.ParseAddr(part); err == nil {
+			a.Networks = append(a.Networks, netip.PrefixFrom(ip, ip.BitLen()))
+			continue
+		}
+		a.Hosts = append(a.Hosts, part)
+	}
+	return a, nil
+}
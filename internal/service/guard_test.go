@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"testing"
+
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllowlist_AllowsIP(t *testing.T) {
+	var a Allowlist
+	blocked := []string{"127.0.0.1", "10.1.2.3", "172.20.0.1", "192.168.1.1", "169.254.169.254", "100.64.0.1", "0.0.0.0", "::1", "fe80::1", "fd00:ec2::254", "::ffff:127.0.0.1",
+		"64:ff9b::7f00:1", "64:ff9b::a9fe:a9fe", "2002:7f00:1::1"}
+	for _, s := range blocked {
+		assert.False(t, a.allowsIP(netip.MustParseAddr(s)), s)
+	}
+	for _, s := range []string{"8.8.8.8", "77.88.55.242", "2a02:6b8::2:242"} {
+		assert.True(t, a.allowsIP(netip.MustParseAddr(s)), s)
+	}
+
+	a = Allowlist{Networks: []netip.Prefix{netip.MustParsePrefix("10.1.0.0/16")}}
+	assert.True(t, a.allowsIP(netip.MustParseAddr("10.1.2.3")))
+	assert.False(t, a.allowsIP(netip.MustParseAddr("10.2.0.1")))
+}
+
+func TestAllowlist_AllowsHost(t *testing.T) {
+	a := Allowlist{Hosts: []string{"Intra.Local"}}
+	assert.True(t, a.allowsHost("intra.local"))
+	assert.True(t, a.allowsHost("api.intra.local."))
+	assert.False(t, a.allowsHost("notintra.local"))
+}
+
+func TestParseAllowlist(t *testing.T) {
+	a, err := ParseAllowlist("10.1.2.3/16, 192.168.1.5,::1, intra.local,")
+	require.NoError(t, err)
+	assert.Equal(t, []netip.Prefix{
+		netip.MustParsePrefix("10.1.0.0/16"),
+		netip.MustParsePrefix("192.168.1.5/32"),
+		netip.MustParsePrefix("::1/128"),
+	}, a.Networks)
+	assert.Equal(t, []string{"intra.local"}, a.Hosts)
+
+	_, err = ParseAllowlist("10.0.0.0/99")
+	assert.Error(t, err)
+}
+
+func TestCheckLink_BlocksPrivateAddress(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	res, err := NewLinkChecker(CheckerConfig{}).CheckLink(context.Background(), srv.URL, models.CheckOptions{})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrBlocked)
+	assert.Equal(t, models.StatusBlocked, res.Status)
+	assert.Equal(t, 1, res.Attempts)
+}
+
+func TestCheckLink_BlocksRedirectToPrivateAddress(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer target.Close()
+
+	// первый хоп идёт на разрешённое имя, редирект уводит на голый IP
+	entry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusFound)
+	}))
+	defer entry.Close()
+	u, err := url.Parse(entry.URL)
+	require.NoError(t, err)
+
+	checker := NewLinkChecker(CheckerConfig{Allow: Allowlist{Hosts: []string{"localhost"}}})
+	res, err := checker.CheckLink(context.Background(), fmt.Sprintf("http://localhost:%s/", u.Port()), models.CheckOptions{})
+	require.Error(t, err)
+
+	assert.Equal(t, models.StatusBlocked, res.Status)
+	require.Len(t, res.Redirects, 1)
+	assert.Equal(t, target.URL, res.Redirects[0].Location)
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// displayLink - ссылка для отчёта. Если хост интернационализированный,
+// показываем его в Unicode и рядом punycode: "https://пример.рф/ [xn--e1afmkfd.xn--p1ai]".
+func displayLink(link string) string {
+	raw := link
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Hostname() == "" {
+		return link
+	}
+
+	unicodeHost, asciiHost, ok := hostForms(u.Hostname())
+	if !ok || unicodeHost == asciiHost {
+		return link
+	}
+
+	// заменяем хост в исходной записи, чтобы не менять остальную часть ссылки
+	host := u.Hostname()
+	shown := strings.Replace(link, host, unicodeHost, 1)
+	return shown + " [" + asciiHost + "]"
+}
+
+// hostForms - Unicode и punycode формы хоста.
+func hostForms(host string) (unicodeHost, asciiHost string, ok bool) {
+	asciiHost, err := idna.Lookup.ToASCII(host)
+	if err != nil {
+		return "", "", false
+	}
+	unicodeHost, err = idna.Lookup.ToUnicode(asciiHost)
+	if err != nil {
+		return "", "", false
+	}
+	return unicodeHost, asciiHost, true
+}
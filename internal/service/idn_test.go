@@ -0,0 +1,25 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDisplayLink(t *testing.T) {
+	tests := []struct {
+		link string
+		want string
+	}{
+		{link: "google.com", want: "google.com"},
+		{link: "https://пример.рф/путь", want: "https://пример.рф/путь [xn--e1afmkfd.xn--p1ai]"},
+		{link: "xn--e1afmkfd.xn--p1ai", want: "пример.рф [xn--e1afmkfd.xn--p1ai]"},
+		{link: "://bad", want: "://bad"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.link, func(t *testing.T) {
+			assert.Equal(t, tt.want, displayLink(tt.link))
+		})
+	}
+}
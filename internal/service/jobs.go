@@ -0,0 +1,340 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/models"
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/storage"
+)
+
+var (
+	// ErrQueueFull - очередь фоновых задач переполнена.
+	ErrQueueFull = errors.New("job queue is full")
+	// ErrQueueStopped - сервис останавливается, задача будет доделана после перезапуска.
+	ErrQueueStopped = errors.New("job queue is stopped")
+	// ErrJobFailed - задачу не удалось завершить.
+	ErrJobFailed = errors.New("job failed")
+
+	errNoSitemapLinks = errors.New("sitemaps contain no links")
+)
+
+// maxFailedJobs - сколько проваленных задач хранится, чтобы клиент мог узнать причину.
+// Старые удаляются из очереди и хранилища, дальше GET /jobs/{id} по ним отвечает 404.
+const maxFailedJobs = 100
+
+// JobQueue - очередь проверок ссылок.
+// Каждая задача сохраняется в хранилище до завершения, поэтому незаконченные проверки
+// переживают остановку сервиса и доделываются после старта (см. Restore).
+// Готовый результат сохраняется в хранилище под номером задачи, после чего задача удаляется из очереди.
+type JobQueue struct {
+	mu      sync.RWMutex
+	jobs    map[int]*models.Job
+	done    map[int]chan struct{}
+	failed  []int // номера проваленных задач по порядку, не больше maxFailedJobs
+	queue   chan int
+	stopped chan struct{}
+	workers int
+	storage storage.Storage
+	checker *LinkChecker
+}
+
+// NewJobQueue - создает очередь. workers - сколько задач обрабатывается одновременно, size - ёмкость очереди.
+func NewJobQueue(s storage.Storage, checker *LinkChecker, workers, size int) *JobQueue {
+	if workers <= 0 {
+		workers = 1
+	}
+	if size <= 0 {
+		size = 100
+	}
+	return &JobQueue{
+		jobs:    make(map[int]*models.Job),
+		done:    make(map[int]chan struct{}),
+		queue:   make(chan int, size),
+		stopped: make(chan struct{}),
+		workers: workers,
+		storage: s,
+		checker: checker,
+	}
+}
+
+// Enqueue - сохраняет задачу, ставит её в очередь и сразу возвращает задачу с выданным номером.
+// Карты сайта не скачиваются: их раскрывает воркер, поэтому Total пока считает только links.
+func (q *JobQueue) Enqueue(ctx context.Context, links []models.LinkSpec, sitemaps []string, opts models.CheckOptions) (models.Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	// место проверяем до выдачи номера, иначе каждый отказ съедал бы номер запроса
+	if len(q.queue) == cap(q.queue) {
+		return models.Job{}, ErrQueueFull
+	}
+
+	num, err := q.storage.NextID(ctx)
+	if err != nil {
+		return models.Job{}, err
+	}
+
+	job := &models.Job{
+		Num:      num,
+		State:    models.JobQueued,
+		Links:    links,
+		Sitemaps: sitemaps,
+		Options:  opts,
+		Total:    len(links),
+	}
+
+	// сначала на диск, потом в очередь: если упадём между ними, задача поднимется при старте
+	if err := q.storage.SaveJob(ctx, *job); err != nil {
+		return models.Job{}, err
+	}
+
+	// очередь могли дозаполнить задачи из Restore, которые кладутся без q.mu,
+	// поэтому не ждём места с блокировкой в руках, а откатываем задачу
+	select {
+	case q.queue <- num:
+	default:
+		if err := q.storage.DeleteJob(ctx, num); err != nil {
+			return models.Job{}, err
+		}
+		return models.Job{}, ErrQueueFull
+	}
+	q.jobs[num] = job
+	q.done[num] = make(chan struct{})
+	return *job, nil
+}
+
+// Restore - возвращает в очередь задачи, которые не успели завершиться до остановки сервиса.
+// Вызывается при старте до Run. Проваленные задачи не перезапускаются, но их статус остаётся виден
+// (последние maxFailedJobs).
+func (q *JobQueue) Restore(ctx context.Context) (int, error) {
+	saved, err := q.storage.Jobs(ctx)
+	if err != nil {
+		return 0, err
+	}
+	// восстанавливаем в порядке поступления
+	slices.SortFunc(saved, func(a, b models.Job) int { return a.Num - b.Num })
+
+	pending := make([]int, 0, len(saved))
+
+	q.mu.Lock()
+	for _, j := range saved {
+		job := j
+		if job.State == models.JobFailed {
+			q.jobs[job.Num] = &job
+			q.failed = append(q.failed, job.Num)
+			continue
+		}
+
+		// прогресс незавершённой задачи начинается заново, карты сайта тоже раскрываются заново
+		job.State = models.JobQueued
+		job.Checked = 0
+		job.Total = len(job.Links)
+		q.jobs[job.Num] = &job
+		q.done[job.Num] = make(chan struct{})
+		pending = append(pending, job.Num)
+	}
+	expired := q.trimFailed()
+	q.mu.Unlock()
+
+	for _, num := range expired {
+		if err := q.storage.DeleteJob(ctx, num); err != nil {
+			return 0, err
+		}
+	}
+
+	// задач может оказаться больше, чем ёмкость очереди, поэтому докладываем их в фоне
+	go func() {
+		for _, num := range pending {
+			select {
+			case q.queue <- num:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return len(pending), nil
+}
+
+// Get - возвращает копию задачи, если она ещё в очереди, в работе или провалена.
+func (q *JobQueue) Get(num int) (models.Job, bool) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	job, ok := q.jobs[num]
+	if !ok {
+		return models.Job{}, false
+	}
+	return *job, true
+}
+
+// Wait - ждёт завершения задачи и возвращает её результат из хранилища.
+// Если очередь остановлена раньше, возвращает ErrQueueStopped: задача останется в хранилище и будет доделана после перезапуска.
+func (q *JobQueue) Wait(ctx context.Context, num int) (models.ResponseSentLinks, error) {
+	q.mu.RLock()
+	done, ok := q.done[num]
+	q.mu.RUnlock()
+
+	if ok {
+		select {
+		case <-done:
+		case <-q.stopped:
+			return models.ResponseSentLinks{}, ErrQueueStopped
+		case <-ctx.Done():
+			return models.ResponseSentLinks{}, ctx.Err()
+		}
+	}
+
+	if job, ok := q.Get(num); ok && job.State == models.JobFailed {
+		return models.ResponseSentLinks{}, fmt.Errorf("%w: %s", ErrJobFailed, job.Error)
+	}
+
+	data, err := q.storage.Get(ctx, []int{num})
+	if err != nil {
+		return models.ResponseSentLinks{}, err
+	}
+	resp, ok := data[num]
+	if !ok {
+		return models.ResponseSentLinks{}, ErrJobFailed
+	}
+	return resp, nil
+}
+
+// Run - запускает воркеров и блокируется до отмены ctx.
+// Задачи, прерванные отменой, остаются в хранилище и будут перезапущены при следующем старте.
+func (q *JobQueue) Run(ctx context.Context) {
+	defer close(q.stopped)
+
+	var wg sync.WaitGroup
+	for range q.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case num := <-q.queue:
+					q.process(ctx, num)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// process - проверяет ссылки одной задачи и сохраняет результат.
+func (q *JobQueue) process(ctx context.Context, num int) {
+	q.mu.Lock()
+	job := q.jobs[num]
+	job.State = models.JobRunning
+	snapshot := *job
+	q.mu.Unlock()
+
+	// ошибка записи состояния не критична: задача в файле уже есть, просто как queued
+	_ = q.storage.SaveJob(ctx, snapshot)
+
+	// карты раскрываются здесь, а не в запросе: скачивание бывает долгим, а задача переживает перезапуск
+	links := snapshot.Links
+	if len(snapshot.Sitemaps) > 0 {
+		found, err := q.checker.ExpandSitemaps(ctx, snapshot.Sitemaps)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil && len(links)+len(found) == 0 {
+			err = errNoSitemapLinks
+		}
+		if err != nil {
+			q.fail(ctx, num, err)
+			return
+		}
+		links = append(slices.Clip(links), found...)
+
+		q.mu.Lock()
+		job.Total = len(links)
+		q.mu.Unlock()
+	}
+
+	var results []models.CheckResult
+	if snapshot.Options.Crawl != nil {
+		// при обходе ссылок становится больше по ходу проверки
+		results = q.checker.Crawl(ctx, links, snapshot.Options, func(checked, total int) {
+			q.mu.Lock()
+			job.Checked, job.Total = checked, total
+			q.mu.Unlock()
+		})
+	} else {
+		results = q.checker.CheckAllWithProgress(ctx, links, snapshot.Options, func() {
+			q.mu.Lock()
+			job.Checked++
+			q.mu.Unlock()
+		})
+	}
+
+	// сервис останавливается — частичный результат не сохраняем, задача доделается после перезапуска
+	if ctx.Err() != nil {
+		return
+	}
+
+	resp := models.ResponseSentLinks{
+		Num:   num,
+		Links: results,
+	}
+
+	if err := q.storage.Save(ctx, resp); err != nil {
+		q.fail(ctx, num, err)
+		return
+	}
+
+	// результат уже в хранилище, дальше статус берётся оттуда
+	_ = q.storage.DeleteJob(ctx, num)
+
+	q.mu.Lock()
+	delete(q.jobs, num)
+	q.finish(num)
+	q.mu.Unlock()
+}
+
+// fail - помечает задачу проваленной, сохраняет её и будит ждущих.
+func (q *JobQueue) fail(ctx context.Context, num int, err error) {
+	q.mu.Lock()
+	job := q.jobs[num]
+	job.State = models.JobFailed
+	job.Error = err.Error()
+	failed := *job
+	q.finish(num)
+	q.failed = append(q.failed, num)
+	expired := q.trimFailed()
+	q.mu.Unlock()
+
+	_ = q.storage.SaveJob(ctx, failed)
+	for _, n := range expired {
+		_ = q.storage.DeleteJob(ctx, n)
+	}
+}
+
+// trimFailed - забывает самые старые проваленные задачи сверх maxFailedJobs и возвращает их номера
+// для удаления из хранилища. Вызывается под q.mu.
+func (q *JobQueue) trimFailed() []int {
+	if len(q.failed) <= maxFailedJobs {
+		return nil
+	}
+	n := len(q.failed) - maxFailedJobs
+	expired := slices.Clone(q.failed[:n])
+	q.failed = slices.Delete(q.failed, 0, n)
+	for _, num := range expired {
+		delete(q.jobs, num)
+	}
+	return expired
+}
+
+// finish - будит всех, кто ждёт задачу. Вызывается под q.mu.
+func (q *JobQueue) finish(num int) {
+	if done, ok := q.done[num]; ok {
+		close(done)
+		delete(q.done, num)
+	}
+}
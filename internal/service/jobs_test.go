@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/models"
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobQueue_ProcessesAndSaves(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	store := storage.NewMemoryStorage()
+	q := NewJobQueue(store, NewLinkChecker(CheckerConfig{Allow: testAllow}), 1, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	job, err := q.Enqueue(ctx, models.LinkSpecs(srv.URL, srv.URL+"/a"), nil, models.CheckOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, models.JobQueued, job.State)
+	assert.Equal(t, 2, job.Total)
+
+	go q.Run(ctx)
+
+	require.Eventually(t, func() bool {
+		_, inQueue := q.Get(job.Num)
+		return !inQueue
+	}, 5*time.Second, 10*time.Millisecond)
+
+	out, err := store.Get(ctx, []int{job.Num})
+	require.NoError(t, err)
+	require.Contains(t, out, job.Num)
+	assert.Len(t, out[job.Num].Links, 2)
+	res, ok := out[job.Num].Find(srv.URL)
+	require.True(t, ok)
+	assert.Equal(t, models.StatusAvailable, res.Status)
+}
+
+func TestJobQueue_Full(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	q := NewJobQueue(store, NewLinkChecker(CheckerConfig{Allow: testAllow}), 1, 1)
+	ctx := context.Background()
+
+	_, err := q.Enqueue(ctx, models.LinkSpecs("ya.ru"), nil, models.CheckOptions{})
+	require.NoError(t, err)
+
+	_, err = q.Enqueue(ctx, models.LinkSpecs("ya.ru"), nil, models.CheckOptions{})
+	assert.ErrorIs(t, err, ErrQueueFull)
+
+	// отказ не должен съедать номер запроса
+	next, err := store.NextID(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, next)
+}
+
+func TestJobQueue_InterruptedJobIsRestored(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+
+	store := storage.NewMemoryStorage()
+	checker := NewLinkChecker(CheckerConfig{Allow: testAllow})
+
+	// первый "запуск": задача начинает выполняться, и сервис останавливается
+	q := NewJobQueue(store, checker, 1, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	job, err := q.Enqueue(ctx, models.LinkSpecs(srv.URL), nil, models.CheckOptions{})
+	require.NoError(t, err)
+
+	runDone := make(chan struct{})
+	go func() {
+		q.Run(ctx)
+		close(runDone)
+	}()
+	require.Eventually(t, func() bool {
+		j, _ := q.Get(job.Num)
+		return j.State == models.JobRunning
+	}, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	<-runDone
+
+	_, err = q.Wait(context.Background(), job.Num)
+	assert.ErrorIs(t, err, ErrQueueStopped)
+
+	saved, err := store.Jobs(context.Background())
+	require.NoError(t, err)
+	require.Len(t, saved, 1)
+	assert.Equal(t, job.Num, saved[0].Num)
+
+	out, err := store.Get(context.Background(), []int{job.Num})
+	require.NoError(t, err)
+	assert.Empty(t, out, "partial result must not be saved")
+
+	// второй "запуск": задача поднимается из хранилища и доделывается
+	close(release)
+	q2 := NewJobQueue(store, checker, 1, 10)
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	defer cancel2()
+
+	restored, err := q2.Restore(ctx2)
+	require.NoError(t, err)
+	assert.Equal(t, 1, restored)
+
+	j, ok := q2.Get(job.Num)
+	require.True(t, ok)
+	assert.Equal(t, models.JobQueued, j.State)
+
+	go q2.Run(ctx2)
+
+	resp, err := q2.Wait(ctx2, job.Num)
+	require.NoError(t, err)
+	assert.Equal(t, job.Num, resp.Num)
+
+	saved, err = store.Jobs(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, saved)
+}
+
+func TestJobQueue_EnqueueDuringRestore(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// восстановленных задач больше, чем места в очереди: Restore докладывает их в фоне
+	for range 50 {
+		num, err := store.NextID(ctx)
+		require.NoError(t, err)
+		require.NoError(t, store.SaveJob(ctx, models.Job{Num: num, State: models.JobQueued, Links: models.LinkSpecs("ya.ru")}))
+	}
+	q := NewJobQueue(store, NewLinkChecker(CheckerConfig{Allow: testAllow}), 1, 2)
+	_, err := q.Restore(ctx)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return len(q.queue) == cap(q.queue) }, 5*time.Second, time.Millisecond)
+
+	// воркеров нет, очередь забита: Enqueue должен отказать, а не повиснуть с q.mu в руках
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 20 {
+			_, err := q.Enqueue(ctx, models.LinkSpecs("ya.ru"), nil, models.CheckOptions{})
+			assert.ErrorIs(t, err, ErrQueueFull)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Enqueue blocked")
+	}
+
+	_, ok := q.Get(1)
+	assert.True(t, ok)
+	saved, err := store.Jobs(ctx)
+	require.NoError(t, err)
+	assert.Len(t, saved, 50, "rejected jobs must not stay in storage")
+}
+
+func TestJobQueue_ExpandsSitemaps(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sitemap.xml":
+			w.Write([]byte(`<urlset><url><loc>` + srv.URL + `/a</loc></url><url><loc>` + srv.URL + `/b</loc></url></urlset>`))
+		case "/a", "/b", "/":
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	store := storage.NewMemoryStorage()
+	q := NewJobQueue(store, NewLinkChecker(CheckerConfig{Allow: testAllow}), 1, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// карты в запросе не скачиваются: задача знает только о присланной ссылке
+	job, err := q.Enqueue(ctx, models.LinkSpecs(srv.URL), []string{srv.URL + "/sitemap.xml"}, models.CheckOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, job.Total)
+
+	failed, err := q.Enqueue(ctx, nil, []string{srv.URL + "/missing.xml"}, models.CheckOptions{})
+	require.NoError(t, err)
+
+	go q.Run(ctx)
+
+	resp, err := q.Wait(ctx, job.Num)
+	require.NoError(t, err)
+	require.Len(t, resp.Links, 3)
+	assert.Equal(t, srv.URL+"/b", resp.Links[2].URL)
+
+	_, err = q.Wait(ctx, failed.Num)
+	assert.ErrorIs(t, err, ErrJobFailed)
+	j, ok := q.Get(failed.Num)
+	require.True(t, ok)
+	assert.Equal(t, models.JobFailed, j.State)
+	assert.Contains(t, j.Error, "missing.xml")
+}
+
+func TestJobQueue_KeepsLastFailedJobs(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	ctx := context.Background()
+
+	for range maxFailedJobs + 5 {
+		num, err := store.NextID(ctx)
+		require.NoError(t, err)
+		require.NoError(t, store.SaveJob(ctx, models.Job{Num: num, State: models.JobFailed, Error: "disk full"}))
+	}
+
+	q := NewJobQueue(store, NewLinkChecker(CheckerConfig{Allow: testAllow}), 1, 10)
+	restored, err := q.Restore(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, restored)
+
+	// самые старые забыты и в очереди, и в хранилище
+	_, ok := q.Get(5)
+	assert.False(t, ok)
+	j, ok := q.Get(6)
+	require.True(t, ok)
+	assert.Equal(t, models.JobFailed, j.State)
+
+	saved, err := store.Jobs(ctx)
+	require.NoError(t, err)
+	assert.Len(t, saved, maxFailedJobs)
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HostLimit - ограничения на один хост.
+type HostLimit struct {
+	// MaxConcurrent - сколько запросов к хосту может идти одновременно, 0 - без ограничения.
+	MaxConcurrent int
+	// MinDelay - минимальная пауза между началом запросов к хосту.
+	MinDelay time.Duration
+}
+
+// HostLimits - ограничения по умолчанию и отдельные правила для доменов.
+// Правило для example.com действует и на его поддомены, лимит у них общий.
+type HostLimits struct {
+	Default HostLimit
+	Domains map[string]HostLimit
+}
+
+// hostIdleTTL - через сколько простоя состояние хоста забывается, чтобы карта хостов не росла бесконечно.
+const hostIdleTTL = time.Minute
+
+// hostState - семафор и время, раньше которого к хосту нельзя отправить следующий запрос.
+type hostState struct {
+	sem  chan struct{}
+	mu   sync.Mutex
+	next time.Time
+
+	// под HostLimiter.mu: сколько запросов сейчас держат состояние и когда отпустили последний
+	users    int
+	lastUsed time.Time
+}
+
+// HostLimiter - ограничивает нагрузку на каждый хост. Один на процесс,
+// поэтому лимит общий для всех запросов и задач, которые проверяются одновременно.
+type HostLimiter struct {
+	limits HostLimits
+
+	mu    sync.Mutex
+	hosts map[string]*hostState
+	swept time.Time
+}
+
+// NewHostLimiter - создаёт HostLimiter. Домены в правилах приводятся к нижнему регистру.
+func NewHostLimiter(limits HostLimits) *HostLimiter {
+	domains := make(map[string]HostLimit, len(limits.Domains))
+	for d, l := range limits.Domains {
+		domains[strings.ToLower(strings.TrimSuffix(d, "."))] = l
+	}
+	limits.Domains = domains
+
+	return &HostLimiter{
+		limits: limits,
+		hosts:  make(map[string]*hostState),
+	}
+}
+
+// Acquire - ждёт своей очереди к хосту и возвращает функцию, которую надо вызвать после запроса.
+// Ошибка возвращается только при отмене ctx.
+func (l *HostLimiter) Acquire(ctx context.Context, host string) (func(), error) {
+	key, limit := l.rule(host)
+	st := l.state(key, limit)
+
+	if st.sem != nil {
+		select {
+		case st.sem <- struct{}{}:
+		case <-ctx.Done():
+			l.put(st)
+			return nil, ctx.Err()
+		}
+	}
+	release := func() {
+		if st.sem != nil {
+			<-st.sem
+		}
+		l.put(st)
+	}
+
+	// занимаем слот по времени сразу, чтобы параллельные запросы выстроились друг за другом
+	st.mu.Lock()
+	now := time.Now()
+	start := now
+	if limit.MinDelay > 0 {
+		if st.next.After(now) {
+			start = st.next
+		}
+		st.next = start.Add(limit.MinDelay)
+	}
+	st.mu.Unlock()
+
+	if wait := start.Sub(now); wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			release()
+			return nil, ctx.Err()
+		}
+	}
+
+	return release, nil
+}
+
+// rule - ключ, по которому считается лимит, и сам лимит. Побеждает самое длинное подходящее правило.
+func (l *HostLimiter) rule(host string) (string, HostLimit) {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+
+	best := ""
+	for d := range l.limits.Domains {
+		if (host == d || strings.HasSuffix(host, "."+d)) && len(d) > len(best) {
+			best = d
+		}
+	}
+	if best != "" {
+		return best, l.limits.Domains[best]
+	}
+	return host, l.limits.Default
+}
+
+// state - состояние хоста, создаётся при первом обращении. Отдаётся занятым, вернуть - через put.
+// Заодно раз в hostIdleTTL забываются простаивающие хосты.
+func (l *HostLimiter) state(key string, limit HostLimit) *hostState {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.swept) > hostIdleTTL {
+		l.swept = now
+		for k, st := range l.hosts {
+			// состояние никем не занято, значит и next никто не двигает
+			if st.users == 0 && now.Sub(st.lastUsed) > hostIdleTTL && !st.next.After(now) {
+				delete(l.hosts, k)
+			}
+		}
+	}
+
+	st, ok := l.hosts[key]
+	if !ok {
+		st = &hostState{}
+		if limit.MaxConcurrent > 0 {
+			st.sem = make(chan struct{}, limit.MaxConcurrent)
+		}
+		l.hosts[key] = st
+	}
+	st.users++
+	return st
+}
+
+// put - отпускает состояние, полученное из state.
+func (l *HostLimiter) put(st *hostState) {
+	l.mu.Lock()
+	st.users--
+	st.lastUsed = time.Now()
+	l.mu.Unlock()
+}
+
+// ParseHostLimits - разбирает правила вида "example.com=2/500ms,intra.local=1".
+// Число - одновременные запросы (0 - без ограничения), после "/" - пауза между запросами.
+func ParseHostLimits(s string) (map[string]HostLimit, error) {
+	limits := make(map[string]HostLimit)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		domain, spec, ok := strings.Cut(part, "=")
+		domain = strings.TrimSpace(domain)
+		if !ok || domain == "" {
+			return nil, fmt.Errorf("host limit %q: want domain=concurrency[/delay]", part)
+		}
+
+		conc, delay, hasDelay := strings.Cut(strings.TrimSpace(spec), "/")
+		var limit HostLimit
+		n, err := strconv.Atoi(conc)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("host limit %q: invalid concurrency %q", part, conc)
+		}
+		limit.MaxConcurrent = n
+		if hasDelay {
+			d, err := time.ParseDuration(delay)
+			if err != nil || d < 0 {
+				return nil, fmt.Errorf("host limit %q: invalid delay %q", part, delay)
+			}
+			limit.MinDelay = d
+		}
+		limits[domain] = limit
+	}
+	return limits, nil
+}
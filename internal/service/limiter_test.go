@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostLimiter_MaxConcurrent(t *testing.T) {
+	l := NewHostLimiter(HostLimits{Default: HostLimit{MaxConcurrent: 2}})
+
+	var inFlight, peak atomic.Int32
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := l.Acquire(context.Background(), "example.com")
+			require.NoError(t, err)
+			defer release()
+
+			n := inFlight.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			inFlight.Add(-1)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(2), peak.Load())
+}
+
+func TestHostLimiter_MinDelay(t *testing.T) {
+	l := NewHostLimiter(HostLimits{Default: HostLimit{MinDelay: 20 * time.Millisecond}})
+
+	start := time.Now()
+	for range 4 {
+		release, err := l.Acquire(context.Background(), "example.com")
+		require.NoError(t, err)
+		release()
+	}
+	// первый запрос без паузы, дальше по 20ms
+	assert.GreaterOrEqual(t, time.Since(start), 60*time.Millisecond)
+
+	// другой хост не ждёт
+	start = time.Now()
+	release, err := l.Acquire(context.Background(), "other.org")
+	require.NoError(t, err)
+	release()
+	assert.Less(t, time.Since(start), 20*time.Millisecond)
+}
+
+func TestHostLimiter_EvictsIdleHosts(t *testing.T) {
+	l := NewHostLimiter(HostLimits{Default: HostLimit{MaxConcurrent: 1}})
+	ctx := context.Background()
+
+	release, err := l.Acquire(ctx, "idle.example.com")
+	require.NoError(t, err)
+	release()
+	held, err := l.Acquire(ctx, "busy.example.com")
+	require.NoError(t, err)
+	defer held()
+
+	// оба хоста давно не трогали, пора подметать
+	l.mu.Lock()
+	for _, st := range l.hosts {
+		st.lastUsed = time.Now().Add(-2 * hostIdleTTL)
+	}
+	l.swept = time.Time{}
+	l.mu.Unlock()
+
+	release, err = l.Acquire(ctx, "other.org")
+	require.NoError(t, err)
+	release()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	assert.NotContains(t, l.hosts, "idle.example.com")
+	// занятый хост остаётся, иначе второй запрос получил бы новый семафор
+	assert.Contains(t, l.hosts, "busy.example.com")
+	assert.Contains(t, l.hosts, "other.org")
+}
+
+func TestHostLimiter_DomainRule(t *testing.T) {
+	l := NewHostLimiter(HostLimits{
+		Default: HostLimit{MaxConcurrent: 5},
+		Domains: map[string]HostLimit{"Intra.Local": {MaxConcurrent: 1}},
+	})
+
+	key, limit := l.rule("api.intra.local")
+	assert.Equal(t, "intra.local", key)
+	assert.Equal(t, 1, limit.MaxConcurrent)
+
+	key, limit = l.rule("notintra.local")
+	assert.Equal(t, "notintra.local", key)
+	assert.Equal(t, 5, limit.MaxConcurrent)
+
+	// поддомены делят один слот с доменом
+	release, err := l.Acquire(context.Background(), "intra.local")
+	require.NoError(t, err)
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = l.Acquire(ctx, "www.intra.local")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestCheckAll_HostLimitSharedAcrossBatches(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	checker := NewLinkChecker(CheckerConfig{
+		Workers:    10,
+		Allow:      testAllow,
+		HostLimits: HostLimits{Default: HostLimit{MaxConcurrent: 2}},
+	})
+
+	links := make([]models.LinkSpec, 10)
+	// разные пути: одинаковые ссылки проверялись бы одним запросом
+	for i := range links {
+		links[i] = models.LinkSpec{URL: fmt.Sprintf("%s/%d", srv.URL, i)}
+	}
+
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, res := range checker.CheckAll(context.Background(), links, models.CheckOptions{}) {
+				assert.Equal(t, models.StatusAvailable, res.Status)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, peak.Load(), int32(2))
+}
+
+func TestParseHostLimits(t *testing.T) {
+	limits, err := ParseHostLimits("intra.local=1/500ms, example.com=3,")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]HostLimit{
+		"intra.local": {MaxConcurrent: 1, MinDelay: 500 * time.Millisecond},
+		"example.com": {MaxConcurrent: 3},
+	}, limits)
+
+	limits, err = ParseHostLimits("")
+	require.NoError(t, err)
+	assert.Empty(t, limits)
+
+	for _, bad := range []string{"example.com", "=2", "example.com=x", "example.com=1/soon", "example.com=-1"} {
+		_, err := ParseHostLimits(bad)
+		assert.Error(t, err, bad)
+	}
+}
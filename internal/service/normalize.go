@@ -0,0 +1,128 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// defaultPorts - порты, которые не пишутся в канонической форме.
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+	"ws":    "80",
+	"wss":   "443",
+	"ftp":   "21",
+}
+
+// rootPathSchemes - схемы, у которых пустой путь равен "/".
+var rootPathSchemes = map[string]bool{"http": true, "https": true, "ws": true, "wss": true, "ftp": true}
+
+// NormalizeURL - каноническая форма ссылки: схема по умолчанию https, хост в нижнем регистре
+// и в punycode, без порта по умолчанию и фрагмента, пустой путь заменён на "/".
+// С sortQuery параметры запроса сортируются по имени.
+// Ссылки без "//" (dns:, mailto:) разбираются отдельно, см. normalizeOpaque.
+func NormalizeURL(link string, sortQuery bool) (string, error) {
+	raw := strings.TrimSpace(link)
+	if raw == "" {
+		return "", errors.New("empty url")
+	}
+	if scheme, rest, ok := strings.Cut(raw, ":"); ok && !strings.HasPrefix(rest, "//") {
+		switch strings.ToLower(scheme) {
+		case "dns", "mailto":
+			return normalizeOpaque(strings.ToLower(scheme), rest)
+		}
+	}
+	// Если нету ://, значит схему не указывали, добавляю.
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid url: %w", err)
+	}
+	if u.Host == "" {
+		return "", errors.New("missing host in URL")
+	}
+
+	host := asciiHost(u.Hostname())
+	port := u.Port()
+	if port == defaultPorts[u.Scheme] {
+		port = ""
+	}
+	switch {
+	case port != "":
+		u.Host = net.JoinHostPort(host, port)
+	case strings.Contains(host, ":"):
+		// IPv6 без порта всё равно в скобках
+		u.Host = "[" + host + "]"
+	default:
+		u.Host = host
+	}
+
+	u.Fragment = ""
+	u.RawFragment = ""
+	if u.Path == "" && u.Opaque == "" && rootPathSchemes[u.Scheme] {
+		u.Path = "/"
+	}
+	if sortQuery && u.RawQuery != "" {
+		// Encode сортирует по имени параметра
+		u.RawQuery = u.Query().Encode()
+	}
+
+	return u.String(), nil
+}
+
+// asciiHost - хост в нижнем регистре, IDN - в punycode. IP-адреса и имена,
+// которые IDNA не принимает (например, с подчёркиванием), только приводятся к нижнему регистру.
+func asciiHost(host string) string {
+	host = strings.ToLower(host)
+	if net.ParseIP(host) != nil {
+		return host
+	}
+	if ascii, err := idna.Lookup.ToASCII(host); err == nil {
+		return ascii
+	}
+	return host
+}
+
+// normalizeOpaque - dns:имя[?type=MX] и mailto:адрес[,адрес]. Домены приводятся так же, как хосты,
+// у dns: остаётся только параметр type, у mailto: параметры (тема письма и т.п.) отбрасываются.
+func normalizeOpaque(scheme, rest string) (string, error) {
+	rest, query, _ := strings.Cut(rest, "?")
+	rest, _, _ = strings.Cut(rest, "#")
+	rest, err := url.PathUnescape(rest)
+	if err != nil {
+		return "", fmt.Errorf("invalid url: %w", err)
+	}
+
+	if scheme == "mailto" {
+		addrs := strings.Split(rest, ",")
+		for i, addr := range addrs {
+			local, domain, ok := strings.Cut(strings.TrimSpace(addr), "@")
+			if !ok || local == "" || domain == "" {
+				return "", fmt.Errorf("invalid mailto address %q", addr)
+			}
+			addrs[i] = local + "@" + asciiHost(strings.TrimSuffix(domain, "."))
+		}
+		return "mailto:" + strings.Join(addrs, ","), nil
+	}
+
+	name := asciiHost(strings.TrimSuffix(rest, "."))
+	if name == "" {
+		return "", errors.New("missing name in dns URL")
+	}
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return "", fmt.Errorf("invalid url: %w", err)
+	}
+	if t := values.Get("type"); t != "" {
+		return "dns:" + name + "?type=" + strings.ToUpper(t), nil
+	}
+	return "dns:" + name, nil
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		in        string
+		sortQuery bool
+		want      string
+	}{
+		{in: "Example.com", want: "https://example.com/"},
+		{in: "https://example.com:443/", want: "https://example.com/"},
+		{in: " example.com ", want: "https://example.com/"},
+		{in: "HTTP://EXAMPLE.com:80/Path?q=1#frag", want: "http://example.com/Path?q=1"},
+		{in: "http://example.com:8080", want: "http://example.com:8080/"},
+		{in: "https://пример.рф/путь", want: "https://xn--e1afmkfd.xn--p1ai/%D0%BF%D1%83%D1%82%D1%8C"},
+		{in: "http://[::1]:80/", want: "http://[::1]/"},
+		{in: "https://example.com/?b=2&a=1", want: "https://example.com/?b=2&a=1"},
+		{in: "https://example.com/?b=2&a=1", sortQuery: true, want: "https://example.com/?a=1&b=2"},
+		{in: "tcp://DB.example.com:5432", want: "tcp://db.example.com:5432"},
+		{in: "wss://example.com:443/socket", want: "wss://example.com/socket"},
+		{in: "ftp://example.com", want: "ftp://example.com/"},
+		{in: "dns:Example.com.?type=mx", want: "dns:example.com?type=MX"},
+		{in: "Mailto:Ops@Example.COM?subject=hi", want: "mailto:Ops@example.com"},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeURL(tt.in, tt.sortQuery)
+		require.NoError(t, err, tt.in)
+		assert.Equal(t, tt.want, got, tt.in)
+	}
+
+	for _, bad := range []string{"", "   ", "http://", "https://exa mple.com/%zz", "dns:", "mailto:nobody"} {
+		_, err := NormalizeURL(bad, false)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestCheckAll_Duplicates(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	links := models.LinkSpecs(srv.URL, srv.URL+"/#top", srv.URL+"/other", " "+srv.URL+" ")
+	results := newTestChecker().CheckAll(context.Background(), links, models.CheckOptions{})
+	require.Len(t, results, 4)
+
+	assert.Equal(t, int32(2), calls.Load())
+	assert.Empty(t, results[0].DuplicateOf)
+	assert.Equal(t, srv.URL+"/", results[0].Canonical)
+
+	assert.Equal(t, srv.URL+"/#top", results[1].URL)
+	assert.Equal(t, srv.URL, results[1].DuplicateOf)
+	assert.Equal(t, models.StatusAvailable, results[1].Status)
+
+	assert.Empty(t, results[2].DuplicateOf)
+	assert.Equal(t, srv.URL, results[3].DuplicateOf)
+
+	for i, res := range results {
+		assert.Equal(t, i+1, res.Position)
+	}
+}
@@ -0,0 +1,310 @@
+package service
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/models"
+	"github.com/jung-kurt/gofpdf"
+)
+
+// Шрифты с поддержкой кириллицы и прочего Unicode, встроены в бинарник.
+var (
+	//go:embed fonts/DejaVuSansCondensed.ttf
+	fontRegular []byte
+	//go:embed fonts/DejaVuSansCondensed-Bold.ttf
+	fontBold []byte
+)
+
+// pdfFont - имя, под которым встроенный шрифт регистрируется в документе.
+const pdfFont = "DejaVu"
+
+// pdfColumn - колонка таблицы отчёта.
+type pdfColumn struct {
+	title string
+	width float64
+	align string
+}
+
+// pdfColumns - ширины в мм, в сумме ширина A4 без полей (190 мм).
+var pdfColumns = []pdfColumn{
+	{title: "URL", width: 84, align: "L"},
+	{title: "Status", width: 32, align: "L"},
+	{title: "Code", width: 14, align: "C"},
+	{title: "Latency, ms", width: 22, align: "R"},
+	{title: "Checked at", width: 38, align: "L"},
+}
+
+const (
+	pdfLineHeight = 5.0
+	pdfMargin     = 10.0
+)
+
+// statusColor - цвет строки таблицы по статусу ссылки.
+func statusColor(s models.LinkStatus) (r, g, b int) {
+	switch s {
+	case models.StatusAvailable:
+		return 226, 243, 228
+	case models.StatusRedirected, models.StatusDegraded:
+		return 255, 246, 214
+	case models.StatusClientError, models.StatusServerError, models.StatusAssertionFailed, models.StatusAnchorMissing:
+		return 250, 220, 218
+	case models.StatusInvalidURL, models.StatusBlocked, models.StatusDisallowed:
+		return 232, 232, 232
+	}
+	// сетевые ошибки: таймауты, DNS, TLS, отказ в соединении
+	return 253, 231, 208
+}
+
+// CreatePDF - создает PDF файл с обработанными ссылками.
+// Каждый запрос - блок со сводкой и таблицей, строки таблицы окрашены по статусу,
+// длинные URL переносятся. На каждой странице повторяется шапка таблицы,
+// внизу - номер страницы и время формирования отчёта.
+// Весь текст выводится встроенным UTF-8 шрифтом, IDN-хосты показываются в Unicode и punycode.
+func CreatePDF(data map[int]models.ResponseSentLinks) ([]byte, error) {
+	generatedAt := time.Now().UTC().Format("2006-01-02 15:04:05 MST")
+
+	// Cоздаю pdf
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddUTF8FontFromBytes(pdfFont, "", fontRegular)
+	pdf.AddUTF8FontFromBytes(pdfFont, "B", fontBold)
+	pdf.SetTitle("Links report", true)
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(false, pdfMargin)
+	pdf.AliasNbPages("")
+
+	// номер запроса, чья таблица сейчас выводится; 0 - вне таблицы
+	tableReq := 0
+
+	pdf.SetHeaderFunc(func() {
+		pdf.SetFont(pdfFont, "B", 14)
+		pdf.CellFormat(0, 8, "Links report", "", 1, "L", false, 0, "")
+		pdf.Ln(2)
+
+		// таблица продолжается с прошлой страницы - повторяем шапку
+		if tableReq != 0 {
+			pdf.SetFont(pdfFont, "B", 10)
+			pdf.CellFormat(0, 6, fmt.Sprintf("Request #%d (continued)", tableReq), "", 1, "L", false, 0, "")
+			drawTableHeader(pdf)
+		}
+	})
+
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-12)
+		pdf.SetFont(pdfFont, "", 8)
+		pdf.CellFormat(95, 6, "Generated at "+generatedAt, "", 0, "L", false, 0, "")
+		pdf.CellFormat(95, 6, fmt.Sprintf("Page %d/{nb}", pdf.PageNo()), "", 0, "R", false, 0, "")
+	})
+
+	pdf.AddPage()
+
+	// проходимся циклом по data в порядке номеров и обрабатываем данные для формирования PDF
+	for _, key := range sortedNums(data) {
+		resp := data[key]
+
+		// заголовок и сводка должны поместиться вместе хотя бы с первой строкой таблицы
+		ensureSpace(pdf, 40)
+
+		pdf.SetFont(pdfFont, "B", 12)
+		pdf.CellFormat(0, 8, fmt.Sprintf("Request #%d", resp.Num), "", 1, "L", false, 0, "")
+		drawSummary(pdf, resp)
+		pdf.Ln(2)
+
+		tableReq = resp.Num
+		drawTableHeader(pdf)
+
+		pdf.SetFont(pdfFont, "", 9)
+		for _, res := range resp.Links {
+			drawRow(pdf, displayLink(res.URL), res)
+		}
+		tableReq = 0
+		drawBrokenLinks(pdf, resp)
+
+		// Пустая строка между блоками запросов
+		pdf.Ln(6)
+	}
+
+	// ошибки шрифтов и вывода копятся внутри документа
+	if err := pdf.Error(); err != nil {
+		return nil, err
+	}
+
+	// Буфер памяти, куда кладется готовый PDF
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+
+	// Возврат байты PDF.
+	return buf.Bytes(), nil
+}
+
+// ensureSpace - переносит вывод на новую страницу, если до нижнего поля меньше h мм.
+func ensureSpace(pdf *gofpdf.Fpdf, h float64) {
+	_, pageH := pdf.GetPageSize()
+	if pdf.GetY()+h > pageH-pdfMargin-12 {
+		pdf.AddPage()
+	}
+}
+
+// drawSummary - сводка по запросу: всего ссылок, рабочих, нерабочих и разбивка по статусам.
+func drawSummary(pdf *gofpdf.Fpdf, resp models.ResponseSentLinks) {
+	counts := make(map[models.LinkStatus]int)
+	okCount, certCount := 0, 0
+	for _, res := range resp.Links {
+		counts[res.Status]++
+		if res.Status.OK() {
+			okCount++
+		}
+		if certWarning(res) {
+			certCount++
+		}
+	}
+
+	pdf.SetFont(pdfFont, "", 10)
+	pdf.CellFormat(0, 5, fmt.Sprintf("Total: %d    OK: %d    Failed: %d", len(resp.Links), okCount, len(resp.Links)-okCount), "", 1, "L", false, 0, "")
+
+	statuses := make([]string, 0, len(counts))
+	for s := range counts {
+		statuses = append(statuses, string(s))
+	}
+	slices.Sort(statuses)
+
+	line := ""
+	for i, s := range statuses {
+		if i > 0 {
+			line += ", "
+		}
+		line += fmt.Sprintf("%s: %d", s, counts[models.LinkStatus(s)])
+	}
+	if line != "" {
+		pdf.MultiCell(0, 5, "By status: "+line, "", "L", false)
+	}
+	if certCount > 0 {
+		pdf.CellFormat(0, 5, fmt.Sprintf("Certificate warnings (expired, expiring or invalid): %d", certCount), "", 1, "L", false, 0, "")
+	}
+}
+
+// maxFoundOnPages - сколько страниц из FoundOn показывать в ячейке таблицы.
+const maxFoundOnPages = 3
+
+// foundOnNote - где найдена ссылка, не больше maxFoundOnPages страниц: общий ресурс вроде /style.css
+// есть на каждой странице обхода, и полный список не влезет в строку. Для нерабочих ссылок
+// все страницы перечислены после таблицы (drawBrokenLinks).
+func foundOnNote(pages []string) string {
+	if len(pages) <= maxFoundOnPages {
+		return "found on " + strings.Join(pages, ", ")
+	}
+	return fmt.Sprintf("found on %s and %d more", strings.Join(pages[:maxFoundOnPages], ", "), len(pages)-maxFoundOnPages)
+}
+
+// drawBrokenLinks - после таблицы: нерабочие ссылки, найденные при обходе, и страницы, где они стоят.
+func drawBrokenLinks(pdf *gofpdf.Fpdf, resp models.ResponseSentLinks) {
+	lines := make([]string, 0)
+	for _, res := range resp.Links {
+		if res.Status.OK() {
+			continue
+		}
+		for _, page := range res.FoundOn {
+			lines = append(lines, fmt.Sprintf("Broken link %s (%s) on page %s", displayLink(res.URL), res.Status, displayLink(page)))
+		}
+	}
+	if len(lines) == 0 {
+		return
+	}
+
+	pdf.Ln(3)
+	ensureSpace(pdf, 16)
+	pdf.SetFont(pdfFont, "B", 10)
+	pdf.CellFormat(0, 6, fmt.Sprintf("Broken links found while crawling: %d", len(lines)), "", 1, "L", false, 0, "")
+	pdf.SetFont(pdfFont, "", 9)
+	for _, line := range lines {
+		ensureSpace(pdf, pdfLineHeight*2)
+		pdf.MultiCell(0, pdfLineHeight, line, "", "L", false)
+	}
+}
+
+// drawTableHeader - шапка таблицы ссылок.
+func drawTableHeader(pdf *gofpdf.Fpdf) {
+	pdf.SetFont(pdfFont, "B", 9)
+	pdf.SetFillColor(60, 64, 67)
+	pdf.SetTextColor(255, 255, 255)
+	for _, c := range pdfColumns {
+		pdf.CellFormat(c.width, 7, c.title, "1", 0, c.align, true, 0, "")
+	}
+	pdf.Ln(-1)
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetFont(pdfFont, "", 9)
+}
+
+// drawRow - строка таблицы. Ячейки переносятся по ширине колонки, высота строки - по самой высокой ячейке.
+func drawRow(pdf *gofpdf.Fpdf, link string, res models.CheckResult) {
+	if res.DuplicateOf != "" {
+		link += "\nduplicate of " + res.DuplicateOf
+	}
+	if len(res.FoundOn) > 0 {
+		link += "\n" + foundOnNote(res.FoundOn)
+	}
+	if res.Source != "" {
+		link += "\nsource: " + res.Source
+	}
+	if note := redirectNote(res); note != "" {
+		link += "\n" + note
+	}
+	if note := certNote(res); note != "" {
+		link += "\n" + note
+	}
+
+	cells := []string{
+		link,
+		string(res.Status),
+		formatCode(res.StatusCode),
+		strconv.FormatInt(res.LatencyMs, 10),
+		formatCheckedAt(res.CheckedAt),
+	}
+
+	wrapped := make([][]string, len(cells))
+	lines := 1
+	for i, c := range cells {
+		wrapped[i] = pdf.SplitText(c, pdfColumns[i].width)
+		if len(wrapped[i]) == 0 {
+			wrapped[i] = []string{""}
+		}
+		lines = max(lines, len(wrapped[i]))
+	}
+	h := float64(lines)*pdfLineHeight + 1
+
+	// строка целиком переезжает на следующую страницу, шапку повторит header
+	ensureSpace(pdf, h)
+
+	r, g, b := statusColor(res.Status)
+	// рабочая ссылка с проблемным сертификатом - предупреждение, а не зелёная строка
+	if res.Status.OK() && certWarning(res) {
+		r, g, b = 255, 235, 156
+	}
+	pdf.SetFillColor(r, g, b)
+
+	x, y := pdf.GetX(), pdf.GetY()
+	for i, c := range pdfColumns {
+		pdf.Rect(x, y, c.width, h, "FD")
+		for j, line := range wrapped[i] {
+			pdf.SetXY(x, y+0.5+float64(j)*pdfLineHeight)
+			pdf.CellFormat(c.width, pdfLineHeight, line, "", 0, c.align, false, 0, "")
+		}
+		x += c.width
+	}
+	pdf.SetXY(pdfMargin, y+h)
+}
+
+// formatCheckedAt - время проверки для таблицы, пусто для старых записей.
+func formatCheckedAt(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format("2006-01-02 15:04:05")
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreatePDF(t *testing.T) {
+	data := map[int]models.ResponseSentLinks{
+		1: {
+			Num: 1,
+			Links: []models.CheckResult{
+				{URL: "google.com", Status: models.StatusAvailable, StatusCode: 200},
+				{URL: "ya.ru", Status: models.StatusDNSFailure, Error: "no such host"},
+			},
+		},
+	}
+
+	pdf, err := CreatePDF(data)
+
+	require.NoError(t, err)
+	require.NotNil(t, pdf)
+	assert.Greater(t, len(pdf), 0)
+
+}
+
+func TestCreatePDF_Unicode(t *testing.T) {
+	data := map[int]models.ResponseSentLinks{
+		1: {
+			Num: 1,
+			Links: []models.CheckResult{
+				{URL: "https://пример.рф/документы", Status: models.StatusAvailable, StatusCode: 200},
+				{URL: "ya.ru", Status: models.StatusDNSFailure, Error: "не найден хост"},
+			},
+		},
+	}
+
+	pdf, err := CreatePDF(data)
+	require.NoError(t, err)
+
+	// шрифт встроен в документ, а не взят из базовых шрифтов PDF
+	assert.True(t, bytes.Contains(pdf, []byte("FontFile2")))
+	assert.True(t, bytes.Contains(pdf, []byte("/BaseFont /utf8dejavu")))
+}
+
+func TestCreatePDF_Pagination(t *testing.T) {
+	links := make([]models.CheckResult, 0, 120)
+	for i := range 120 {
+		link := fmt.Sprintf("https://example.com/%d/very/long/path/that/does/not/fit/into/the/url/column/of/the/report/table?query=%d", i, i)
+		links = append(links, models.CheckResult{
+			URL:        link,
+			Status:     []models.LinkStatus{models.StatusAvailable, models.StatusClientError, models.StatusTimeout}[i%3],
+			StatusCode: 200,
+			LatencyMs:  int64(i),
+			CheckedAt:  time.Date(2025, 11, 11, 10, 0, 0, 0, time.UTC),
+		})
+	}
+	data := map[int]models.ResponseSentLinks{
+		1: {Num: 1, Links: links},
+		2: {Num: 2, Links: []models.CheckResult{{URL: "ya.ru", Status: models.StatusAvailable}}},
+	}
+
+	pdf, err := CreatePDF(data)
+	require.NoError(t, err)
+
+	pages := bytes.Count(pdf, []byte("/Type /Page\n")) + bytes.Count(pdf, []byte("/Type /Page>>"))
+	assert.Greater(t, pages, 3, "long URLs must wrap and spill over several pages")
+}
+
+func TestStatusColor(t *testing.T) {
+	ok := [3]int{}
+	ok[0], ok[1], ok[2] = statusColor(models.StatusAvailable)
+	fail := [3]int{}
+	fail[0], fail[1], fail[2] = statusColor(models.StatusServerError)
+	assert.NotEqual(t, ok, fail)
+}
+
+func TestCreatePDF_CrawlFindings(t *testing.T) {
+	data := map[int]models.ResponseSentLinks{
+		1: {
+			Num: 1,
+			Links: []models.CheckResult{
+				{URL: "https://example.com/", Status: models.StatusAvailable, StatusCode: 200},
+				{URL: "https://example.com/missing", Status: models.StatusClientError, StatusCode: 404, Depth: 1,
+					FoundOn: []string{"https://example.com/", "https://example.com/about"}},
+			},
+		},
+	}
+
+	pdf, err := CreatePDF(data)
+	require.NoError(t, err)
+	assert.Greater(t, len(pdf), 0)
+}
+
+func TestFoundOnNote(t *testing.T) {
+	assert.Equal(t, "found on /a, /b", foundOnNote([]string{"/a", "/b"}))
+
+	pages := make([]string, 100)
+	for i := range pages {
+		pages[i] = fmt.Sprintf("/p%d", i)
+	}
+	assert.Equal(t, "found on /p0, /p1, /p2 and 97 more", foundOnNote(pages))
+
+	// общий ресурс со ста страниц обхода не ломает таблицу
+	data := map[int]models.ResponseSentLinks{1: {Num: 1, Links: []models.CheckResult{
+		{URL: "https://example.com/style.css", Status: models.StatusAvailable, StatusCode: 200, Depth: 1, FoundOn: pages},
+	}}}
+	_, err := CreatePDF(data)
+	require.NoError(t, err)
+}
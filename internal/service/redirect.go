@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/models"
+	"golang.org/x/net/publicsuffix"
+)
+
+// maxFetchRedirects - сколько редиректов проходит скачивание служебных файлов (robots.txt, карты сайта).
+// RFC 9309 просит для robots.txt не меньше пяти.
+const maxFetchRedirects = 5
+
+// fetchFollow - GET с переходом по редиректам, не больше maxFetchRedirects.
+// Для robots.txt и карт сайта важен итоговый файл, а не цепочка, поэтому переходы не записываются.
+func (c *LinkChecker) fetchFollow(ctx context.Context, u *url.URL, bodyLimit int64) (*response, error) {
+	for hops := 0; ; hops++ {
+		var t models.Timings
+		resp, err := c.send(ctx, http.MethodGet, u, &t, bodyLimit)
+		if err != nil {
+			return nil, err
+		}
+		next, ok := redirectTarget(u, resp.Response)
+		if !ok {
+			return resp, nil
+		}
+		if hops == maxFetchRedirects {
+			return nil, fmt.Errorf("more than %d redirects", maxFetchRedirects)
+		}
+		if next.Scheme != "http" && next.Scheme != "https" {
+			return nil, fmt.Errorf("redirect to %w: %s", ErrUnsupportedScheme, next.Scheme)
+		}
+		u = next
+	}
+}
+
+// redirectTarget - куда ведёт ответ 3xx. Относительный Location разрешается от текущего URL.
+func redirectTarget(current *url.URL, resp *http.Response) (*url.URL, bool) {
+	switch resp.StatusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+	default:
+		return nil, false
+	}
+
+	loc := resp.Header.Get("Location")
+	if loc == "" {
+		return nil, false
+	}
+	next, err := current.Parse(loc)
+	if err != nil {
+		return nil, false
+	}
+	// фрагмент не уходит на сервер и не влияет на поиск петель
+	next.Fragment = ""
+	return next, true
+}
+
+// sameSite - принадлежат ли адреса одному регистрируемому домену (www.example.com и example.com - да).
+// IP-адреса и хосты без публичного суффикса сравниваются целиком.
+func sameSite(a, b *url.URL) bool {
+	ha, hb := strings.ToLower(a.Hostname()), strings.ToLower(b.Hostname())
+	if ha == hb {
+		return true
+	}
+	if net.ParseIP(ha) != nil || net.ParseIP(hb) != nil {
+		return false
+	}
+
+	da, errA := publicsuffix.EffectiveTLDPlusOne(ha)
+	db, errB := publicsuffix.EffectiveTLDPlusOne(hb)
+	if errA != nil || errB != nil {
+		return false
+	}
+	return da == db
+}
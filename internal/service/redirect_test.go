@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// redirectServer - /a -> /b -> /c (200), /loop1 <-> /loop2, /far -> target.
+func redirectServer(t *testing.T, target string) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/a", http.RedirectHandler("/b", http.StatusMovedPermanently))
+	mux.Handle("/b", http.RedirectHandler("/c", http.StatusFound))
+	mux.HandleFunc("/c", func(w http.ResponseWriter, r *http.Request) {})
+	mux.Handle("/loop1", http.RedirectHandler("/loop2", http.StatusFound))
+	mux.Handle("/loop2", http.RedirectHandler("/loop1", http.StatusFound))
+	mux.HandleFunc("/far", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target, http.StatusTemporaryRedirect)
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestCheckLink_RedirectChain(t *testing.T) {
+	srv := redirectServer(t, "")
+
+	res, err := newTestChecker().CheckLink(context.Background(), srv.URL+"/a", models.CheckOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, models.StatusRedirected, res.Status)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, srv.URL+"/c", res.FinalURL)
+	require.Len(t, res.Redirects, 2)
+	assert.Equal(t, models.RedirectHop{URL: srv.URL + "/a", StatusCode: http.StatusMovedPermanently, Location: srv.URL + "/b"}, res.Redirects[0])
+	assert.Equal(t, models.RedirectHop{URL: srv.URL + "/b", StatusCode: http.StatusFound, Location: srv.URL + "/c"}, res.Redirects[1])
+	assert.False(t, res.CrossDomain)
+}
+
+func TestCheckLink_RedirectLoop(t *testing.T) {
+	srv := redirectServer(t, "")
+
+	res, err := newTestChecker().CheckLink(context.Background(), srv.URL+"/loop1", models.CheckOptions{})
+	require.Error(t, err)
+
+	assert.Equal(t, models.StatusRedirectError, res.Status)
+	assert.True(t, res.RedirectLoop)
+	assert.Len(t, res.Redirects, 2)
+}
+
+func TestCheckLink_TooManyRedirects(t *testing.T) {
+	srv := redirectServer(t, "")
+
+	res, err := newTestChecker().CheckLink(context.Background(), srv.URL+"/a", models.CheckOptions{MaxRedirects: 1})
+	require.Error(t, err)
+
+	assert.Equal(t, models.StatusRedirectError, res.Status)
+	assert.True(t, res.TooManyRedirects)
+}
+
+func TestCheckLink_RedirectPolicyNone(t *testing.T) {
+	srv := redirectServer(t, "")
+
+	res, err := newTestChecker().CheckLink(context.Background(), srv.URL+"/a", models.CheckOptions{RedirectPolicy: models.RedirectNone})
+	require.NoError(t, err)
+
+	assert.Equal(t, models.StatusRedirected, res.Status)
+	assert.Equal(t, http.StatusMovedPermanently, res.StatusCode)
+	assert.Equal(t, srv.URL+"/a", res.FinalURL)
+	assert.Len(t, res.Redirects, 1)
+}
+
+func TestCheckLink_RedirectPolicyNoneWithExpect(t *testing.T) {
+	srv := redirectServer(t, "")
+	c := newTestChecker()
+
+	// ожидали 200, а получили 301, дальше которого не идём
+	opts := models.CheckOptions{RedirectPolicy: models.RedirectNone, Expect: &models.Expectations{Status: []int{200}}}
+	res, err := c.CheckLink(context.Background(), srv.URL+"/a", opts)
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusAssertionFailed, res.Status)
+	assert.Equal(t, http.StatusMovedPermanently, res.StatusCode)
+	assert.NotEmpty(t, res.AssertionFailures)
+	assert.NotEmpty(t, res.Error)
+
+	opts.Expect = &models.Expectations{Status: []int{301}}
+	res, err = c.CheckLink(context.Background(), srv.URL+"/a", opts)
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusRedirected, res.Status)
+	assert.Empty(t, res.AssertionFailures)
+}
+
+func TestCheckLink_CrossDomainAndSameHostPolicy(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer target.Close()
+	// тот же сервер, но под другим именем хоста
+	otherHost := strings.Replace(target.URL, "127.0.0.1", "localhost", 1)
+
+	srv := redirectServer(t, otherHost+"/landing")
+
+	res, err := newTestChecker().CheckLink(context.Background(), srv.URL+"/far", models.CheckOptions{})
+	require.NoError(t, err)
+	assert.True(t, res.CrossDomain)
+	assert.Equal(t, otherHost+"/landing", res.FinalURL)
+
+	res, err = newTestChecker().CheckLink(context.Background(), srv.URL+"/far", models.CheckOptions{RedirectPolicy: models.RedirectSameHost})
+	require.NoError(t, err)
+	assert.True(t, res.CrossDomain)
+	assert.Equal(t, http.StatusTemporaryRedirect, res.StatusCode)
+	assert.Equal(t, srv.URL+"/far", res.FinalURL)
+}
+
+func TestSameSite(t *testing.T) {
+	parse := func(s string) *url.URL {
+		u, err := url.Parse(s)
+		require.NoError(t, err)
+		return u
+	}
+
+	assert.True(t, sameSite(parse("https://example.com/old"), parse("https://www.example.com/new")))
+	assert.True(t, sameSite(parse("https://a.example.co.uk"), parse("http://b.example.co.uk")))
+	assert.False(t, sameSite(parse("https://example.com"), parse("https://parked-domain.net")))
+	assert.False(t, sameSite(parse("http://127.0.0.1:8080"), parse("http://localhost:8080")))
+}
@@ -0,0 +1,278 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/models"
+)
+
+// PDFRenderer - отчёт в PDF, см. CreatePDF.
+type PDFRenderer struct{}
+
+func (PDFRenderer) ContentType() string { return "application/pdf" }
+func (PDFRenderer) Extension() string   { return "pdf" }
+
+func (PDFRenderer) Render(data map[int]models.ResponseSentLinks) ([]byte, error) {
+	return CreatePDF(data)
+}
+
+// JSONRenderer - список запросов по возрастанию номера.
+type JSONRenderer struct{}
+
+func (JSONRenderer) ContentType() string { return "application/json" }
+func (JSONRenderer) Extension() string   { return "json" }
+
+func (JSONRenderer) Render(data map[int]models.ResponseSentLinks) ([]byte, error) {
+	out := make([]models.ResponseSentLinks, 0, len(data))
+	for _, n := range sortedNums(data) {
+		out = append(out, data[n])
+	}
+	return json.MarshalIndent(out, "", "  ")
+}
+
+// CSVRenderer - одна строка на ссылку, удобно для CI.
+type CSVRenderer struct{}
+
+func (CSVRenderer) ContentType() string { return "text/csv" }
+func (CSVRenderer) Extension() string   { return "csv" }
+
+func (CSVRenderer) Render(data map[int]models.ResponseSentLinks) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	header := []string{"links_num", "link", "status", "status_code", "final_url", "method", "latency_ms", "error", "checked_at", "redirects", "cert", "canonical", "duplicate_of", "found_on", "source"}
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+
+	for _, n := range sortedNums(data) {
+		resp := data[n]
+		for _, res := range resp.Links {
+			link := res.URL
+			row := []string{
+				strconv.Itoa(resp.Num),
+				link,
+				string(res.Status),
+				formatCode(res.StatusCode),
+				res.FinalURL,
+				res.Method,
+				strconv.FormatInt(res.LatencyMs, 10),
+				res.Error,
+				formatTime(res.CheckedAt),
+				redirectNote(res),
+				certNote(res),
+				res.Canonical,
+				res.DuplicateOf,
+				strings.Join(res.FoundOn, " "),
+				res.Source,
+			}
+			if err := w.Write(row); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// MarkdownRenderer - таблица на каждый запрос, для вики.
+type MarkdownRenderer struct{}
+
+func (MarkdownRenderer) ContentType() string { return "text/markdown; charset=utf-8" }
+func (MarkdownRenderer) Extension() string   { return "md" }
+
+func (MarkdownRenderer) Render(data map[int]models.ResponseSentLinks) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("# Links report\n")
+
+	for _, n := range sortedNums(data) {
+		resp := data[n]
+		fmt.Fprintf(&buf, "\n## Request #%d\n\n", resp.Num)
+		buf.WriteString("| Link | Status | Code | Latency, ms | Error |\n")
+		buf.WriteString("|------|--------|------|-------------|-------|\n")
+
+		for _, res := range resp.Links {
+			link := res.URL
+			errText := res.Error
+			if note := redirectNote(res); note != "" {
+				errText = strings.TrimSpace(errText + " " + note)
+			}
+			fmt.Fprintf(&buf, "| %s | %s | %s | %d | %s |\n",
+				escapeMarkdown(link), res.Status, formatCode(res.StatusCode), res.LatencyMs, escapeMarkdown(errText))
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// escapeMarkdown - экранирует символы, ломающие таблицу.
+func escapeMarkdown(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", " ")
+}
+
+// HTMLRenderer - самостоятельная HTML-страница.
+type HTMLRenderer struct{}
+
+func (HTMLRenderer) ContentType() string { return "text/html; charset=utf-8" }
+func (HTMLRenderer) Extension() string   { return "html" }
+
+var htmlReport = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Links report</title>
+<style>
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+.ok { background: #e6f4ea; }
+.fail { background: #fce8e6; }
+</style>
+</head>
+<body>
+<h1>Links report</h1>
+{{range .}}<h2>Request #{{.Num}}</h2>
+<table>
+<tr><th>Link</th><th>Status</th><th>Code</th><th>Latency, ms</th><th>Error</th></tr>
+{{range .Rows}}<tr class="{{if .Result.Status.OK}}ok{{else}}fail{{end}}"><td>{{.Link}}</td><td>{{.Result.Status}}</td><td>{{if .Result.StatusCode}}{{.Result.StatusCode}}{{end}}</td><td>{{.Result.LatencyMs}}</td><td>{{.Result.Error}}</td></tr>
+{{end}}</table>
+{{end}}</body>
+</html>
+`))
+
+type htmlRow struct {
+	Link   string
+	Result models.CheckResult
+}
+
+type htmlRequest struct {
+	Num  int
+	Rows []htmlRow
+}
+
+func (HTMLRenderer) Render(data map[int]models.ResponseSentLinks) ([]byte, error) {
+	reqs := make([]htmlRequest, 0, len(data))
+	for _, n := range sortedNums(data) {
+		resp := data[n]
+		req := htmlRequest{Num: resp.Num}
+		for _, res := range resp.Links {
+			req.Rows = append(req.Rows, htmlRow{Link: res.URL, Result: res})
+		}
+		reqs = append(reqs, req)
+	}
+
+	var buf bytes.Buffer
+	if err := htmlReport.Execute(&buf, reqs); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// JUnitRenderer - JUnit XML: каждый запрос - test suite, каждая ссылка - test case.
+// Нерабочие ссылки становятся упавшими тестами.
+type JUnitRenderer struct{}
+
+func (JUnitRenderer) ContentType() string { return "application/xml" }
+func (JUnitRenderer) Extension() string   { return "xml" }
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func (JUnitRenderer) Render(data map[int]models.ResponseSentLinks) ([]byte, error) {
+	out := junitTestSuites{}
+
+	for _, n := range sortedNums(data) {
+		resp := data[n]
+		suite := junitTestSuite{Name: fmt.Sprintf("Request #%d", resp.Num)}
+		var total int64
+
+		for _, res := range resp.Links {
+			link := res.URL
+			total += res.LatencyMs
+
+			tc := junitTestCase{
+				Name:      link,
+				ClassName: fmt.Sprintf("links.request%d", resp.Num),
+				Time:      formatSeconds(res.LatencyMs),
+			}
+			if !res.Status.OK() {
+				msg := string(res.Status)
+				if res.StatusCode != 0 {
+					msg = fmt.Sprintf("%s (%d)", msg, res.StatusCode)
+				}
+				tc.Failure = &junitFailure{Message: msg, Type: string(res.Status), Text: res.Error}
+				suite.Failures++
+			}
+			suite.Cases = append(suite.Cases, tc)
+		}
+
+		suite.Tests = len(suite.Cases)
+		suite.Time = formatSeconds(total)
+		out.Tests += suite.Tests
+		out.Failures += suite.Failures
+		out.Suites = append(out.Suites, suite)
+	}
+
+	b, err := xml.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), b...), nil
+}
+
+// formatCode - пустая строка вместо нулевого кода.
+func formatCode(code int) string {
+	if code == 0 {
+		return ""
+	}
+	return strconv.Itoa(code)
+}
+
+// formatTime - время проверки в RFC3339, пустое для старых записей.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// formatSeconds - миллисекунды в секунды для JUnit.
+func formatSeconds(ms int64) string {
+	return strconv.FormatFloat(float64(ms)/1000, 'f', 3, 64)
+}
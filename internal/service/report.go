@@ -0,0 +1,215 @@
+package service
+
+import (
+	"fmt"
+	"mime"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/models"
+)
+
+// Renderer - формирует отчёт по проверенным ссылкам в одном из форматов.
+type Renderer interface {
+	// ContentType - MIME-тип готового отчёта.
+	ContentType() string
+	// Extension - расширение файла для Content-Disposition.
+	Extension() string
+	// Render - собирает отчёт по запросам из хранилища.
+	Render(data map[int]models.ResponseSentLinks) ([]byte, error)
+}
+
+// DefaultFormat - формат отчёта, если клиент ничего не попросил.
+const DefaultFormat = "pdf"
+
+// renderers - все поддерживаемые форматы по имени для ?format=.
+var renderers = map[string]Renderer{
+	"pdf":      PDFRenderer{},
+	"json":     JSONRenderer{},
+	"csv":      CSVRenderer{},
+	"html":     HTMLRenderer{},
+	"markdown": MarkdownRenderer{},
+	"md":       MarkdownRenderer{},
+	"junit":    JUnitRenderer{},
+}
+
+// mediaTypes - какой формат отдавать на конкретный тип из заголовка Accept.
+var mediaTypes = map[string]string{
+	"application/pdf":       "pdf",
+	"application/json":      "json",
+	"text/csv":              "csv",
+	"text/html":             "html",
+	"text/markdown":         "markdown",
+	"text/x-markdown":       "markdown",
+	"application/xml":       "junit",
+	"text/xml":              "junit",
+	"application/junit+xml": "junit",
+}
+
+// RendererByFormat - выбирает формат по имени из ?format=.
+func RendererByFormat(format string) (Renderer, bool) {
+	r, ok := renderers[strings.ToLower(strings.TrimSpace(format))]
+	return r, ok
+}
+
+// NegotiateRenderer - выбирает формат по заголовку Accept с учётом q-весов.
+// Пустой заголовок и */* дают формат по умолчанию. Если ничего не подошло, возвращает false.
+func NegotiateRenderer(accept string) (Renderer, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return renderers[DefaultFormat], true
+	}
+
+	type candidate struct {
+		mediaType string
+		q         float64
+	}
+
+	candidates := make([]candidate, 0)
+	for _, part := range strings.Split(accept, ",") {
+		mt, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		candidates = append(candidates, candidate{mediaType: mt, q: q})
+	}
+
+	// при равных весах сохраняем порядок клиента
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+
+	for _, c := range candidates {
+		if c.mediaType == "*/*" {
+			return renderers[DefaultFormat], true
+		}
+		if format, ok := mediaTypes[c.mediaType]; ok {
+			return renderers[format], true
+		}
+		// text/* и подобные: первый подходящий тип в стабильном порядке
+		if prefix, ok := strings.CutSuffix(c.mediaType, "/*"); ok {
+			types := make([]string, 0, len(mediaTypes))
+			for mt := range mediaTypes {
+				types = append(types, mt)
+			}
+			slices.Sort(types)
+			for _, mt := range types {
+				if strings.HasPrefix(mt, prefix+"/") {
+					return renderers[mediaTypes[mt]], true
+				}
+			}
+		}
+	}
+	return nil, false
+}
+
+// redirectNote - краткое описание цепочки редиректов для отчётов: конечный URL и флаги.
+// Пусто, если редиректов не было.
+func redirectNote(res models.CheckResult) string {
+	if len(res.Redirects) == 0 {
+		return ""
+	}
+
+	flags := make([]string, 0, 4)
+	if res.RedirectLoop {
+		flags = append(flags, "loop")
+	}
+	if res.TooManyRedirects {
+		flags = append(flags, "too many redirects")
+	}
+	if res.CrossDomain {
+		flags = append(flags, "cross-domain")
+	}
+	if res.HTTPSDowngrade {
+		flags = append(flags, "https->http")
+	}
+
+	note := fmt.Sprintf("-> %s (%d hops)", res.FinalURL, len(res.Redirects))
+	if len(flags) > 0 {
+		note += " [" + strings.Join(flags, ", ") + "]"
+	}
+	return note
+}
+
+// certNote - краткое описание сертификата для отчётов: кому и кем выдан, до какого числа, проблемы.
+func certNote(res models.CheckResult) string {
+	cert := res.Cert
+	if cert == nil {
+		return ""
+	}
+
+	note := fmt.Sprintf("cert: %s by %s, expires %s (%d d)", cert.Subject, cert.Issuer, cert.NotAfter.Format("2006-01-02"), cert.DaysLeft)
+	switch {
+	case cert.Expired:
+		note += " [expired]"
+	case cert.Expiring:
+		note += " [expiring soon]"
+	}
+	if cert.VerifyError != "" {
+		note += "; " + cert.VerifyError
+	}
+	return note
+}
+
+// certWarning - сертификат истёк, скоро истечёт или не прошёл проверку.
+func certWarning(res models.CheckResult) bool {
+	return res.Cert != nil && (res.Cert.Expired || res.Cert.Expiring || res.Cert.VerifyError != "")
+}
+
+// sortedNums - номера запросов по возрастанию, чтобы отчёт был стабильным.
+func sortedNums(data map[int]models.ResponseSentLinks) []int {
+	nums := make([]int, 0, len(data))
+	for k := range data {
+		nums = append(nums, k)
+	}
+	slices.Sort(nums)
+	return nums
+}
+
+// ReportOrder - порядок ссылок внутри запроса в отчёте.
+type ReportOrder string
+
+const (
+	// OrderSubmitted - как ссылки были присланы (по умолчанию).
+	OrderSubmitted ReportOrder = "submitted"
+	// OrderSorted - по алфавиту.
+	OrderSorted ReportOrder = "sorted"
+)
+
+// ParseReportOrder - порядок из ?order=. Пустая строка - порядок отправки.
+func ParseReportOrder(s string) (ReportOrder, bool) {
+	switch ReportOrder(strings.ToLower(strings.TrimSpace(s))) {
+	case "", OrderSubmitted:
+		return OrderSubmitted, true
+	case OrderSorted:
+		return OrderSorted, true
+	}
+	return "", false
+}
+
+// OrderLinks - данные для отчёта с ссылками в нужном порядке.
+// Возвращает копию, сами данные из хранилища не меняются. Рендереры выводят ссылки как есть.
+func OrderLinks(data map[int]models.ResponseSentLinks, order ReportOrder) map[int]models.ResponseSentLinks {
+	if order != OrderSorted {
+		return data
+	}
+
+	out := make(map[int]models.ResponseSentLinks, len(data))
+	for n, resp := range data {
+		resp.Links = slices.Clone(resp.Links)
+		// при одинаковых ссылках сохраняется порядок отправки
+		slices.SortStableFunc(resp.Links, func(a, b models.CheckResult) int {
+			return strings.Compare(a.URL, b.URL)
+		})
+		out[n] = resp
+	}
+	return out
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func reportData() map[int]models.ResponseSentLinks {
+	return map[int]models.ResponseSentLinks{
+		2: {
+			Num: 2,
+			Links: []models.CheckResult{
+				{URL: "ya.ru", Status: models.StatusServerError, StatusCode: 503, Error: "503 Service Unavailable"},
+			},
+		},
+		1: {
+			Num: 1,
+			Links: []models.CheckResult{
+				{URL: "google.com", Status: models.StatusAvailable, StatusCode: 200, LatencyMs: 120},
+				{URL: "bad|link.io", Status: models.StatusDNSFailure, Error: "no such host"},
+			},
+		},
+	}
+}
+
+func TestNegotiateRenderer(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   string
+		ok     bool
+	}{
+		{accept: "", want: "pdf", ok: true},
+		{accept: "*/*", want: "pdf", ok: true},
+		{accept: "application/json", want: "json", ok: true},
+		{accept: "text/csv;q=0.5, text/markdown", want: "md", ok: true},
+		{accept: "text/html;q=0.2, application/xml;q=0.9", want: "xml", ok: true},
+		{accept: "image/png, */*;q=0.1", want: "pdf", ok: true},
+		{accept: "image/png", ok: false},
+		{accept: "application/json;q=0", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			r, ok := NegotiateRenderer(tt.accept)
+			require.Equal(t, tt.ok, ok)
+			if ok {
+				assert.Equal(t, tt.want, r.Extension())
+			}
+		})
+	}
+}
+
+func TestRendererByFormat(t *testing.T) {
+	for _, format := range []string{"pdf", "json", "csv", "html", "markdown", "md", "junit", "JSON"} {
+		_, ok := RendererByFormat(format)
+		assert.True(t, ok, format)
+	}
+	_, ok := RendererByFormat("docx")
+	assert.False(t, ok)
+}
+
+func TestJSONRenderer(t *testing.T) {
+	b, err := JSONRenderer{}.Render(reportData())
+	require.NoError(t, err)
+
+	var out []models.ResponseSentLinks
+	require.NoError(t, json.Unmarshal(b, &out))
+	require.Len(t, out, 2)
+	assert.Equal(t, 1, out[0].Num)
+	assert.Equal(t, 2, out[1].Num)
+}
+
+func TestCSVRenderer(t *testing.T) {
+	b, err := CSVRenderer{}.Render(reportData())
+	require.NoError(t, err)
+
+	rows, err := csv.NewReader(strings.NewReader(string(b))).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 4)
+	assert.Equal(t, "links_num", rows[0][0])
+	// ссылки в порядке отправки
+	assert.Equal(t, []string{"1", "google.com", "available", "200"}, rows[1][:4])
+	assert.Equal(t, []string{"1", "bad|link.io", "dns_failure", ""}, rows[2][:4])
+	assert.Equal(t, []string{"2", "ya.ru", "server_error", "503"}, rows[3][:4])
+}
+
+func TestOrderLinks(t *testing.T) {
+	data := reportData()
+
+	sorted := OrderLinks(data, OrderSorted)
+	assert.Equal(t, "bad|link.io", sorted[1].Links[0].URL)
+	assert.Equal(t, "google.com", sorted[1].Links[1].URL)
+	// исходные данные не тронуты
+	assert.Equal(t, "google.com", data[1].Links[0].URL)
+
+	assert.Equal(t, data, OrderLinks(data, OrderSubmitted))
+
+	for in, want := range map[string]ReportOrder{"": OrderSubmitted, "submitted": OrderSubmitted, "Sorted": OrderSorted} {
+		got, ok := ParseReportOrder(in)
+		assert.True(t, ok, in)
+		assert.Equal(t, want, got, in)
+	}
+	_, ok := ParseReportOrder("random")
+	assert.False(t, ok)
+}
+
+func TestMarkdownRenderer(t *testing.T) {
+	b, err := MarkdownRenderer{}.Render(reportData())
+	require.NoError(t, err)
+
+	md := string(b)
+	assert.Contains(t, md, "## Request #1")
+	assert.Contains(t, md, `bad\|link.io`)
+	assert.Contains(t, md, "| google.com | available | 200 | 120 |  |")
+}
+
+func TestHTMLRenderer(t *testing.T) {
+	b, err := HTMLRenderer{}.Render(reportData())
+	require.NoError(t, err)
+
+	page := string(b)
+	assert.Contains(t, page, "<h2>Request #2</h2>")
+	assert.Contains(t, page, `class="fail"`)
+	assert.Contains(t, page, `class="ok"`)
+}
+
+func TestJUnitRenderer(t *testing.T) {
+	b, err := JUnitRenderer{}.Render(reportData())
+	require.NoError(t, err)
+
+	var out junitTestSuites
+	require.NoError(t, xml.Unmarshal(b, &out))
+	assert.Equal(t, 3, out.Tests)
+	assert.Equal(t, 2, out.Failures)
+	require.Len(t, out.Suites, 2)
+	assert.Equal(t, "Request #1", out.Suites[0].Name)
+	require.NotNil(t, out.Suites[1].Cases[0].Failure)
+	assert.Equal(t, "server_error (503)", out.Suites[1].Cases[0].Failure.Message)
+}
+
+func TestRedirectNote(t *testing.T) {
+	assert.Empty(t, redirectNote(models.CheckResult{Status: models.StatusAvailable}))
+
+	res := models.CheckResult{
+		Status:         models.StatusRedirected,
+		FinalURL:       "http://parked.net/",
+		Redirects:      []models.RedirectHop{{URL: "https://example.com/old", StatusCode: 301, Location: "http://parked.net/"}},
+		CrossDomain:    true,
+		HTTPSDowngrade: true,
+	}
+	assert.Equal(t, "-> http://parked.net/ (1 hops) [cross-domain, https->http]", redirectNote(res))
+}
+
+func TestCertNote(t *testing.T) {
+	assert.Empty(t, certNote(models.CheckResult{Status: models.StatusAvailable}))
+
+	res := models.CheckResult{
+		Status: models.StatusAvailable,
+		Cert: &models.CertInfo{
+			Subject:  "example.com",
+			Issuer:   "R3",
+			NotAfter: time.Date(2025, 11, 12, 0, 0, 0, 0, time.UTC),
+			DaysLeft: 1,
+			Expiring: true,
+		},
+	}
+	assert.Equal(t, "cert: example.com by R3, expires 2025-11-12 (1 d) [expiring soon]", certNote(res))
+	assert.True(t, certWarning(res))
+
+	res.Cert.Expiring = false
+	assert.False(t, certWarning(res))
+}
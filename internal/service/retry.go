@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/models"
+)
+
+// RetryPolicy - повторы при временных ошибках: таймаутах, обрывах соединения и ответах 429/502/503/504.
+type RetryPolicy struct {
+	// MaxAttempts - всего попыток, 1 - без повторов.
+	MaxAttempts int
+	// BaseDelay - пауза перед первым повтором, дальше удваивается.
+	BaseDelay time.Duration
+	// MaxDelay - потолок паузы. Retry-After больше этого значения не ждём и прекращаем повторы.
+	MaxDelay time.Duration
+}
+
+// withDefaults - значения по умолчанию для незаданных полей.
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = 1
+	}
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = 200 * time.Millisecond
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = 5 * time.Second
+	}
+	return p
+}
+
+// backoff - экспоненциальная пауза перед повтором номер attempt (с 1) со случайным разбросом в половину паузы.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.BaseDelay << (attempt - 1)
+	if d <= 0 || d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	half := d / 2
+	return half + rand.N(half+1)
+}
+
+// doWithRetry - выполняет запрос, повторяя его при временных ошибках.
+// Возвращает последний ответ или ошибку и число сделанных попыток, в t - тайминги последней попытки.
+func (c *LinkChecker) doWithRetry(ctx context.Context, u *url.URL, t *models.Timings, bodyLimit int64) (*response, int, error) {
+	policy := c.cfg.Retry
+
+	for attempt := 1; ; attempt++ {
+		resp, err := c.doRequest(ctx, u, t, bodyLimit)
+		if attempt >= policy.MaxAttempts || !retryable(resp, err) {
+			return resp, attempt, err
+		}
+
+		delay := policy.backoff(attempt)
+		if resp != nil {
+			if after, ok := retryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+				// сервер просит ждать дольше, чем мы готовы - отдаём то, что есть
+				if after > policy.MaxDelay {
+					return resp, attempt, err
+				}
+				delay = after
+			}
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return resp, attempt, err
+		case <-timer.C:
+		}
+	}
+}
+
+// retryable - стоит ли повторить запрос.
+func retryable(resp *response, err error) bool {
+	if err != nil {
+		// отмену снаружи не повторяем
+		if errors.Is(err, context.Canceled) {
+			return false
+		}
+		var netErr interface{ Timeout() bool }
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return true
+		}
+		return errors.Is(err, syscall.ECONNRESET) ||
+			errors.Is(err, io.EOF) ||
+			errors.Is(err, io.ErrUnexpectedEOF)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryAfter - разбирает заголовок Retry-After: число секунд или HTTP-дата.
+func retryAfter(v string, now time.Time) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		d := t.Sub(now)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func retryChecker(attempts int) *LinkChecker {
+	return NewLinkChecker(CheckerConfig{Allow: testAllow, Retry: RetryPolicy{
+		MaxAttempts: attempts,
+		BaseDelay:   5 * time.Millisecond,
+		MaxDelay:    50 * time.Millisecond,
+	}})
+}
+
+func TestCheckLink_RetriesTransientStatus(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	res, err := retryChecker(3).CheckLink(context.Background(), srv.URL, models.CheckOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, models.StatusAvailable, res.Status)
+	assert.Equal(t, 3, res.Attempts)
+}
+
+func TestCheckLink_RetriesExhausted(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	res, err := retryChecker(2).CheckLink(context.Background(), srv.URL, models.CheckOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, models.StatusServerError, res.Status)
+	assert.Equal(t, 2, res.Attempts)
+}
+
+func TestCheckLink_NoRetryOnClientError(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	res, err := retryChecker(3).CheckLink(context.Background(), srv.URL, models.CheckOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, res.Attempts)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestCheckLink_HonorsRetryAfter(t *testing.T) {
+	var first atomic.Int64
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			first.Store(time.Now().UnixNano())
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	// Retry-After: 0 перекрывает экспоненциальную паузу
+	checker := NewLinkChecker(CheckerConfig{Allow: testAllow, Retry: RetryPolicy{MaxAttempts: 2, BaseDelay: time.Second, MaxDelay: 2 * time.Second}})
+	start := time.Now()
+	res, err := checker.CheckLink(context.Background(), srv.URL, models.CheckOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, models.StatusAvailable, res.Status)
+	assert.Equal(t, 2, res.Attempts)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+}
+
+func TestCheckLink_RetryAfterTooLong(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	res, err := retryChecker(3).CheckLink(context.Background(), srv.URL, models.CheckOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, models.StatusServerError, res.Status)
+	assert.Equal(t, 1, res.Attempts)
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2025, 11, 11, 10, 0, 0, 0, time.UTC)
+
+	d, ok := retryAfter("120", now)
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Minute, d)
+
+	d, ok = retryAfter(now.Add(30*time.Second).Format(http.TimeFormat), now)
+	assert.True(t, ok)
+	assert.Equal(t, 30*time.Second, d)
+
+	_, ok = retryAfter("soon", now)
+	assert.False(t, ok)
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}.withDefaults()
+
+	for attempt, limit := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 3: 400 * time.Millisecond, 10: time.Second} {
+		d := p.backoff(attempt)
+		assert.GreaterOrEqual(t, d, limit/2)
+		assert.LessOrEqual(t, d, limit)
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	assert.True(t, retryable(nil, context.DeadlineExceeded))
+	assert.False(t, retryable(nil, context.Canceled))
+	assert.False(t, retryable(nil, errors.New("boom")))
+	assert.True(t, retryable(&response{Response: &http.Response{StatusCode: http.StatusGatewayTimeout}}, nil))
+	assert.False(t, retryable(&response{Response: &http.Response{StatusCode: http.StatusInternalServerError}}, nil))
+}
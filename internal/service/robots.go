@@ -0,0 +1,247 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// maxRobotsBytes - сколько robots.txt читается, остальное игнорируется (RFC 9309 требует не меньше 500 КиБ).
+	maxRobotsBytes = 512 << 10
+	// robotsTTL - сколько помнить robots.txt сайта.
+	robotsTTL = time.Hour
+)
+
+// robotsRule - одна строка Allow или Disallow.
+type robotsRule struct {
+	allow   bool
+	pattern string
+	re      *regexp.Regexp
+}
+
+// newRobotsRule - шаблон пути в регулярку: "*" - любая последовательность, "$" в конце - конец пути.
+func newRobotsRule(allow bool, pattern string) robotsRule {
+	expr := regexp.QuoteMeta(strings.TrimSuffix(pattern, "$"))
+	expr = "^" + strings.ReplaceAll(expr, `\*`, ".*")
+	if strings.HasSuffix(pattern, "$") {
+		expr += "$"
+	}
+	return robotsRule{allow: allow, pattern: pattern, re: regexp.MustCompile(expr)}
+}
+
+// robotsRules - правила из robots.txt для нашего User-Agent.
+type robotsRules struct {
+	rules []robotsRule
+	delay time.Duration
+}
+
+// allowed - можно ли ходить по пути (вместе с query). Побеждает самое длинное совпавшее правило,
+// при равной длине - Allow. Без совпадений можно.
+func (r robotsRules) allowed(path string) bool {
+	best, allow := -1, true
+	for _, rule := range r.rules {
+		if !rule.re.MatchString(path) {
+			continue
+		}
+		if n := len(rule.pattern); n > best || (n == best && rule.allow) {
+			best, allow = n, rule.allow
+		}
+	}
+	return allow
+}
+
+// parseRobots - правила группы для agent, а если её нет - группы "*".
+// Группа - подряд идущие строки User-agent и следующие за ними правила.
+func parseRobots(r io.Reader, agent string) robotsRules {
+	agent = strings.ToLower(agent)
+
+	var (
+		specific, generic robotsRules
+		hasSpecific       bool
+		// к кому относятся текущие правила
+		forAgent, forAny bool
+		inAgents         bool
+	)
+
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line, _, _ := strings.Cut(sc.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		if key == "user-agent" {
+			if !inAgents {
+				forAgent, forAny = false, false
+			}
+			inAgents = true
+			ua := strings.ToLower(value)
+			switch {
+			case ua == "*":
+				forAny = true
+			case ua != "" && strings.Contains(agent, ua):
+				forAgent, hasSpecific = true, true
+			}
+			continue
+		}
+		inAgents = false
+
+		apply := func(f func(*robotsRules)) {
+			if forAgent {
+				f(&specific)
+			}
+			if forAny {
+				f(&generic)
+			}
+		}
+		switch key {
+		case "allow", "disallow":
+			// пустой Disallow ничего не запрещает
+			if value == "" {
+				continue
+			}
+			rule := newRobotsRule(key == "allow", value)
+			apply(func(r *robotsRules) { r.rules = append(r.rules, rule) })
+		case "crawl-delay":
+			secs, err := strconv.ParseFloat(value, 64)
+			if err != nil || secs <= 0 {
+				continue
+			}
+			d := time.Duration(secs * float64(time.Second))
+			apply(func(r *robotsRules) { r.delay = d })
+		}
+	}
+
+	if hasSpecific {
+		return specific
+	}
+	return generic
+}
+
+// robotsEntry - robots.txt сайта в кэше.
+// next - раньше этого времени следующий запрос с Crawl-delay не отправляется; пропадает вместе с записью.
+type robotsEntry struct {
+	rules   robotsRules
+	expires time.Time
+	next    time.Time
+}
+
+// robotsCache - robots.txt по origin (схема и хост).
+type robotsCache struct {
+	mu        sync.Mutex
+	items     map[string]robotsEntry
+	lastSweep time.Time
+}
+
+// set - сохраняет правила сайта на robotsTTL, заодно раз в robotsTTL выбрасывает протухшие записи.
+func (r *robotsCache) set(origin string, rules robotsRules) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(r.lastSweep) > robotsTTL {
+		for k, e := range r.items {
+			if now.After(e.expires) {
+				delete(r.items, k)
+			}
+		}
+		r.lastSweep = now
+	}
+	r.items[origin] = robotsEntry{rules: rules, expires: now.Add(robotsTTL)}
+}
+
+// robots - правила robots.txt для сайта ссылки. Файл скачивается раз в robotsTTL,
+// одновременные запросы за одним файлом объединяются.
+// Если файла нет или его не удалось получить, можно всё: саму ссылку проверка всё равно покажет.
+func (c *LinkChecker) robots(ctx context.Context, u *url.URL) robotsRules {
+	origin := u.Scheme + "://" + u.Host
+
+	c.robotsCache.mu.Lock()
+	e, ok := c.robotsCache.items[origin]
+	c.robotsCache.mu.Unlock()
+	if ok && time.Now().Before(e.expires) {
+		return e.rules
+	}
+
+	v, _, _ := c.flight.Do("robots "+origin, func() (any, error) {
+		rules := c.fetchRobots(ctx, &url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/robots.txt"})
+
+		// не дождались файла из-за дедлайна - в следующий раз попробуем снова
+		if ctx.Err() == nil {
+			c.robotsCache.set(origin, rules)
+		}
+		return rules, nil
+	})
+	return v.(robotsRules)
+}
+
+// fetchRobots - скачивает и разбирает robots.txt, проходя редиректы (http -> https, example.com -> www).
+func (c *LinkChecker) fetchRobots(ctx context.Context, u *url.URL) robotsRules {
+	resp, err := c.fetchFollow(ctx, u, maxRobotsBytes)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return robotsRules{}
+	}
+	agent, _, _ := strings.Cut(c.cfg.UserAgent, "/")
+	return parseRobots(bytes.NewReader(resp.Body), agent)
+}
+
+// robotsAllowed - можно ли проверять ссылку по robots.txt.
+func (c *LinkChecker) robotsAllowed(ctx context.Context, u *url.URL) bool {
+	rules := c.robots(ctx, u)
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	return rules.allowed(path)
+}
+
+// robotsWait - выдерживает Crawl-delay сайта перед запросом. Ждут только проверки с opts.Robots,
+// пауза не больше c.cfg.MaxCrawlDelay и забывается вместе с robots.txt (robotsTTL), общий лимитер хоста она не трогает.
+// Ошибка - только отмена ctx.
+func (c *LinkChecker) robotsWait(ctx context.Context, u *url.URL) error {
+	origin := u.Scheme + "://" + u.Host
+
+	c.robotsCache.mu.Lock()
+	e, ok := c.robotsCache.items[origin]
+	now := time.Now()
+	if !ok || e.rules.delay <= 0 || !now.Before(e.expires) {
+		c.robotsCache.mu.Unlock()
+		return nil
+	}
+	// занимаем слот сразу, чтобы параллельные проверки выстроились друг за другом
+	start := now
+	if e.next.After(now) {
+		start = e.next
+	}
+	e.next = start.Add(min(e.rules.delay, c.cfg.MaxCrawlDelay))
+	c.robotsCache.items[origin] = e
+	c.robotsCache.mu.Unlock()
+
+	wait := start.Sub(now)
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
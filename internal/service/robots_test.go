@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRobots(t *testing.T) {
+	robots := `
+# общие правила
+User-agent: *
+Disallow: /private
+Allow: /private/public
+Disallow: /*.pdf$
+Crawl-delay: 1
+
+User-agent: otherbot
+User-agent: LinkChecker
+Disallow: /tmp/
+Crawl-delay: 0.5
+`
+	generic := parseRobots(strings.NewReader(robots), "somebot")
+	assert.False(t, generic.allowed("/private/x"))
+	assert.True(t, generic.allowed("/private/public/x"))
+	assert.False(t, generic.allowed("/docs/file.pdf"))
+	assert.True(t, generic.allowed("/docs/file.pdf?download=1"))
+	assert.True(t, generic.allowed("/"))
+	assert.Equal(t, time.Second, generic.delay)
+
+	// своя группа целиком заменяет общую
+	own := parseRobots(strings.NewReader(robots), "linkchecker")
+	assert.True(t, own.allowed("/private/x"))
+	assert.False(t, own.allowed("/tmp/a"))
+	assert.Equal(t, 500*time.Millisecond, own.delay)
+
+	// пустой Disallow ничего не запрещает
+	open := parseRobots(strings.NewReader("User-agent: *\nDisallow:\n"), "linkchecker")
+	assert.True(t, open.allowed("/anything"))
+}
+
+func TestCheckLink_Robots(t *testing.T) {
+	var robotsCalls atomic.Int32
+	var agent atomic.Value
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			robotsCalls.Add(1)
+			w.Write([]byte("User-agent: linkchecker\nDisallow: /private\n"))
+			return
+		}
+		agent.Store(r.UserAgent())
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	checker := newTestChecker()
+	opts := models.CheckOptions{Robots: true}
+
+	res, err := checker.CheckLink(context.Background(), srv.URL+"/private/page", opts)
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusDisallowed, res.Status)
+	assert.NotEmpty(t, res.Error)
+
+	res, err = checker.CheckLink(context.Background(), srv.URL+"/public", opts)
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusAvailable, res.Status)
+	assert.Equal(t, DefaultUserAgent, agent.Load())
+	// robots.txt скачивается один раз на сайт
+	assert.Equal(t, int32(1), robotsCalls.Load())
+
+	// без режима robots.txt не смотрим
+	res, err = checker.CheckLink(context.Background(), srv.URL+"/private/page", models.CheckOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusAvailable, res.Status)
+}
+
+func TestCheckLink_RobotsCrawlDelay(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			w.Write([]byte("User-agent: *\nCrawl-delay: 0.2\n"))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	checker := newTestChecker()
+	start := time.Now()
+	links := models.LinkSpecs(srv.URL+"/a", srv.URL+"/b", srv.URL+"/c")
+	for _, res := range checker.CheckAll(context.Background(), links, models.CheckOptions{Robots: true}) {
+		assert.Equal(t, models.StatusAvailable, res.Status)
+	}
+	// три запроса с паузой 200ms между ними
+	assert.GreaterOrEqual(t, time.Since(start), 400*time.Millisecond)
+}
+
+func TestCheckLink_RobotsCrawlDelayLimits(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			w.Write([]byte("User-agent: *\nCrawl-delay: 3600\n"))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	checker := NewLinkChecker(CheckerConfig{Allow: testAllow, MaxCrawlDelay: 100 * time.Millisecond})
+	start := time.Now()
+	links := models.LinkSpecs(srv.URL+"/a", srv.URL+"/b", srv.URL+"/c")
+	for _, res := range checker.CheckAll(context.Background(), links, models.CheckOptions{Robots: true}) {
+		assert.Equal(t, models.StatusAvailable, res.Status)
+	}
+	// пауза урезана до MaxCrawlDelay
+	elapsed := time.Since(start)
+	assert.GreaterOrEqual(t, elapsed, 200*time.Millisecond)
+	assert.Less(t, elapsed, 5*time.Second)
+
+	// проверки без режима robots паузу сайта не ждут
+	start = time.Now()
+	res, err := checker.CheckLink(context.Background(), srv.URL+"/d", models.CheckOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusAvailable, res.Status)
+	assert.Less(t, time.Since(start), 90*time.Millisecond)
+}
+
+func TestCheckLink_RobotsRedirect(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			http.Redirect(w, r, "/static/robots.txt", http.StatusMovedPermanently)
+		case "/static/robots.txt":
+			w.Write([]byte("User-agent: *\nDisallow: /private\n"))
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer srv.Close()
+
+	res, err := newTestChecker().CheckLink(context.Background(), srv.URL+"/private/page", models.CheckOptions{Robots: true})
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusDisallowed, res.Status)
+}
+
+func TestRobotsCache_SweepsExpired(t *testing.T) {
+	c := robotsCache{items: map[string]robotsEntry{
+		"https://old.example.com": {expires: time.Now().Add(-time.Second)},
+	}}
+
+	c.set("https://new.example.com", robotsRules{})
+
+	assert.NotContains(t, c.items, "https://old.example.com")
+	assert.Contains(t, c.items, "https://new.example.com")
+}
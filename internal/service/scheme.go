@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/models"
+)
+
+// ErrUnsupportedScheme - для схемы ссылки нет зарегистрированной проверки.
+var ErrUnsupportedScheme = errors.New("unsupported scheme")
+
+// Checker - проверка ссылок одной схемы.
+// Ссылка приходит уже в канонической форме (NormalizeURL). URL, время проверки и задержку
+// заполняет LinkChecker, проверке достаточно статуса, кода и причины ошибки.
+type Checker interface {
+	Check(ctx context.Context, u *url.URL, opts models.CheckOptions) (models.CheckResult, error)
+}
+
+// CheckerFunc - обычная функция в роли Checker.
+type CheckerFunc func(ctx context.Context, u *url.URL, opts models.CheckOptions) (models.CheckResult, error)
+
+// Check - вызывает f.
+func (f CheckerFunc) Check(ctx context.Context, u *url.URL, opts models.CheckOptions) (models.CheckResult, error) {
+	return f(ctx, u, opts)
+}
+
+// registerDefaults - проверки, которые есть из коробки.
+func (c *LinkChecker) registerDefaults() {
+	c.Register(CheckerFunc(c.checkHTTP), "http", "https")
+	c.Register(CheckerFunc(c.checkWebSocket), "ws", "wss")
+	c.Register(CheckerFunc(c.checkTCP), "tcp")
+	c.Register(CheckerFunc(c.checkFTP), "ftp")
+	c.Register(CheckerFunc(c.checkDNS), "dns")
+	c.Register(CheckerFunc(c.checkMailto), "mailto")
+}
+
+// Register - назначает проверку для схем, заменяя прежнюю.
+// Вызывается до начала проверок: реестр не защищён от одновременной записи.
+func (c *LinkChecker) Register(ch Checker, schemes ...string) {
+	for _, s := range schemes {
+		c.checkers[strings.ToLower(s)] = ch
+	}
+}
+
+// Schemes - схемы, для которых есть проверка, по алфавиту.
+func (c *LinkChecker) Schemes() []string {
+	schemes := make([]string, 0, len(c.checkers))
+	for s := range c.checkers {
+		schemes = append(schemes, s)
+	}
+	slices.Sort(schemes)
+	return schemes
+}
+
+// checkLink - проверяет ссылку по сети, без кэша: выбирает проверку по схеме.
+// Результат заполнен всегда, ошибка дублирует причину недоступности для логирования.
+func (c *LinkChecker) checkLink(ctx context.Context, link string, opts models.CheckOptions) (models.CheckResult, error) {
+	start := time.Now()
+
+	var res models.CheckResult
+	u, err := url.Parse(link)
+	if err == nil {
+		if ch, ok := c.checkers[u.Scheme]; ok {
+			res, err = ch.Check(ctx, u, opts)
+		} else {
+			err = fmt.Errorf("%w: %s", ErrUnsupportedScheme, u.Scheme)
+			res = models.CheckResult{Status: models.StatusInvalidURL, Error: err.Error()}
+		}
+	} else {
+		err = fmt.Errorf("invalid url: %w", err)
+		res = models.CheckResult{Status: models.StatusInvalidURL, Error: err.Error()}
+	}
+
+	res.URL = link
+	res.CheckedAt = start.UTC()
+	res.LatencyMs = time.Since(start).Milliseconds()
+	return res, err
+}
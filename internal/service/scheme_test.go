@@ -0,0 +1,215 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeResolver - DNS без сети: записи по имени.
+type fakeResolver struct {
+	hosts map[string][]string
+	mx    map[string][]*net.MX
+}
+
+func (r fakeResolver) LookupHost(_ context.Context, host string) ([]string, error) {
+	if addrs, ok := r.hosts[host]; ok {
+		return addrs, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+func (r fakeResolver) LookupIP(ctx context.Context, network, host string) ([]net.IP, error) {
+	addrs, err := r.LookupHost(ctx, host)
+	var ips []net.IP
+	for _, a := range addrs {
+		ip := net.ParseIP(a)
+		if (network == "ip4") == (ip.To4() != nil) {
+			ips = append(ips, ip)
+		}
+	}
+	return ips, err
+}
+
+func (r fakeResolver) LookupMX(_ context.Context, name string) ([]*net.MX, error) {
+	if mx, ok := r.mx[name]; ok {
+		return mx, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+}
+
+func (r fakeResolver) LookupCNAME(_ context.Context, host string) (string, error) {
+	return host + ".", nil
+}
+
+func TestLinkChecker_Schemes(t *testing.T) {
+	assert.Equal(t, []string{"dns", "ftp", "http", "https", "mailto", "tcp", "ws", "wss"}, newTestChecker().Schemes())
+}
+
+func TestLinkChecker_Register(t *testing.T) {
+	checker := newTestChecker()
+	var got string
+	checker.Register(CheckerFunc(func(_ context.Context, u *url.URL, _ models.CheckOptions) (models.CheckResult, error) {
+		got = u.String()
+		return models.CheckResult{Status: models.StatusAvailable}, nil
+	}), "gopher")
+
+	res, err := checker.CheckLink(context.Background(), "gopher://Example.com/1", models.CheckOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "gopher://example.com/1", got)
+	assert.Equal(t, models.StatusAvailable, res.Status)
+	assert.Equal(t, "gopher://Example.com/1", res.URL)
+	assert.False(t, res.CheckedAt.IsZero())
+}
+
+func TestCheckLink_TCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	res, err := newTestChecker().CheckLink(context.Background(), "tcp://"+addr, models.CheckOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusAvailable, res.Status)
+	require.NotNil(t, res.Timings)
+
+	// порт закрыт
+	ln.Close()
+	res, err = newTestChecker().CheckLink(context.Background(), "tcp://"+addr, models.CheckOptions{})
+	require.Error(t, err)
+	assert.Equal(t, models.StatusConnectionRefused, res.Status)
+
+	// без разрешения внутренние адреса недоступны
+	res, err = NewLinkChecker(CheckerConfig{}).CheckLink(context.Background(), "tcp://"+addr, models.CheckOptions{})
+	require.Error(t, err)
+	assert.Equal(t, models.StatusBlocked, res.Status)
+
+	res, err = newTestChecker().CheckLink(context.Background(), "tcp://example.com", models.CheckOptions{})
+	require.ErrorIs(t, err, errNoPort)
+	assert.Equal(t, models.StatusInvalidURL, res.Status)
+}
+
+func TestCheckLink_FTP(t *testing.T) {
+	tests := []struct {
+		name       string
+		greeting   string
+		wantStatus models.LinkStatus
+		wantCode   int
+	}{
+		{name: "ready", greeting: "220 FTP server ready", wantStatus: models.StatusAvailable, wantCode: 220},
+		{name: "busy", greeting: "421 Too many connections", wantStatus: models.StatusServerError, wantCode: 421},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			defer ln.Close()
+			go func() {
+				conn, err := ln.Accept()
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+				conn.Write([]byte(tt.greeting + "\r\n"))
+				bufio.NewReader(conn).ReadString('\n')
+			}()
+
+			res, err := newTestChecker().CheckLink(context.Background(), "ftp://"+ln.Addr().String(), models.CheckOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, res.Status)
+			assert.Equal(t, tt.wantCode, res.StatusCode)
+		})
+	}
+}
+
+func TestCheckLink_WebSocket(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/socket" || !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+			http.Error(w, "not a websocket", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Connection", "Upgrade")
+		w.Header().Set("Upgrade", "websocket")
+		w.Header().Set("Sec-WebSocket-Accept", websocketAccept(r.Header.Get("Sec-WebSocket-Key")))
+		w.WriteHeader(http.StatusSwitchingProtocols)
+	}))
+	defer srv.Close()
+
+	base := "ws://" + strings.TrimPrefix(srv.URL, "http://")
+
+	res, err := newTestChecker().CheckLink(context.Background(), base+"/socket", models.CheckOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusAvailable, res.Status)
+	assert.Equal(t, http.StatusSwitchingProtocols, res.StatusCode)
+
+	res, err = newTestChecker().CheckLink(context.Background(), base+"/other", models.CheckOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusClientError, res.Status)
+}
+
+func TestCheckLink_DNS(t *testing.T) {
+	checker := NewLinkChecker(CheckerConfig{Resolver: fakeResolver{
+		hosts: map[string][]string{"example.com": {"93.184.216.34", "2606:2800:220:1::"}},
+		mx:    map[string][]*net.MX{"example.com": {{Host: "mx.example.com.", Pref: 10}}},
+	}})
+
+	tests := []struct {
+		link        string
+		wantStatus  models.LinkStatus
+		wantRecords []string
+	}{
+		{link: "dns:example.com", wantStatus: models.StatusAvailable, wantRecords: []string{"93.184.216.34", "2606:2800:220:1::"}},
+		{link: "dns:example.com?type=AAAA", wantStatus: models.StatusAvailable, wantRecords: []string{"2606:2800:220:1::"}},
+		{link: "dns:example.com?type=MX", wantStatus: models.StatusAvailable, wantRecords: []string{"mx.example.com 10"}},
+		{link: "dns:example.com?type=CNAME", wantStatus: models.StatusDNSFailure},
+		{link: "dns:missing.example?type=A", wantStatus: models.StatusDNSFailure},
+		{link: "dns:example.com?type=TXT", wantStatus: models.StatusInvalidURL},
+	}
+	for _, tt := range tests {
+		res, err := checker.CheckLink(context.Background(), tt.link, models.CheckOptions{})
+		assert.Equal(t, tt.wantStatus, res.Status, tt.link)
+		assert.Equal(t, tt.wantRecords, res.Records, tt.link)
+		assert.Equal(t, tt.wantStatus != models.StatusAvailable, err != nil, tt.link)
+	}
+}
+
+func TestCheckLink_Mailto(t *testing.T) {
+	checker := NewLinkChecker(CheckerConfig{Resolver: fakeResolver{mx: map[string][]*net.MX{
+		"example.com": {{Host: "mx1.example.com.", Pref: 10}},
+		"nomail.org":  {{Host: ".", Pref: 0}},
+	}}})
+
+	res, err := checker.CheckLink(context.Background(), "mailto:Ops@Example.com?subject=hi", models.CheckOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusAvailable, res.Status)
+	assert.Equal(t, []string{"example.com mx1.example.com 10"}, res.Records)
+
+	res, err = checker.CheckLink(context.Background(), "mailto:a@example.com,b@nomail.org", models.CheckOptions{})
+	require.Error(t, err)
+	assert.Equal(t, models.StatusDNSFailure, res.Status)
+	assert.Contains(t, res.Error, "null MX")
+
+	res, err = checker.CheckLink(context.Background(), "mailto:ops@missing.example", models.CheckOptions{})
+	var dnsErr *net.DNSError
+	require.True(t, errors.As(err, &dnsErr))
+	assert.Equal(t, models.StatusDNSFailure, res.Status)
+}
@@ -1,141 +1,220 @@
 package service
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
-	"slices"
 	"strings"
 	"time"
 
 	"github.com/NailUsmanov/linkchecker11_11_2025/internal/models"
-	"github.com/jung-kurt/gofpdf"
 )
 
-var client = &http.Client{Timeout: 5 * time.Second}
-
-// CheckLink - возвращает статус ссылки или ошибку.
-func CheckLink(ctx context.Context, link string) (bool, error) {
-	// Принимаю и нормализую URL
-	rawURL := strings.TrimSpace(link)
-	if rawURL == "" {
-		return false, errors.New("empty url")
-	}
-	// Если нету ://, значит схему не указывали, добавляю.
-	if !strings.Contains(rawURL, "://") {
-		rawURL = "https://" + rawURL
+// checkHTTP - проверка http и https ссылок.
+// Редиректы обрабатываются по политике из opts, каждый переход записывается в результат.
+// Временные ошибки повторяются по политике c.cfg.Retry, число попыток тоже попадает в результат.
+// Для https записывается сертификат сервера, даже если он не прошёл проверку.
+// Тайминги берутся из последнего запроса; ответ 2xx медленнее c.cfg.SlowThreshold получает статус degraded.
+// Если в opts есть ожидания, последний ответ читается GET-ом и проверяется (см. applyExpectations).
+// С opts.Robots каждый хоп сначала сверяется с robots.txt сайта.
+func (c *LinkChecker) checkHTTP(ctx context.Context, u *url.URL, opts models.CheckOptions) (res models.CheckResult, err error) {
+	res = models.CheckResult{Status: models.StatusInvalidURL}
+
+	fail := func(err error) (models.CheckResult, error) {
+		res.Error = err.Error()
+		return res, err
 	}
-
-	// Достаю сам УРЛ
-	u, err := url.Parse(rawURL)
-	if err != nil {
-		return false, fmt.Errorf("invalid url: %w", err)
-	}
-
-	if u.Scheme != "http" && u.Scheme != "https" {
-		return false, fmt.Errorf("unsupported scheme: %s", err)
+	// сетевые ошибки раскладываем по категориям
+	failNet := func(err error) (models.CheckResult, error) {
+		res.Status = classifyError(err)
+		return fail(err)
 	}
 
 	if u.Host == "" {
-		return false, errors.New("missing host in URL")
+		return fail(errors.New("missing host in URL"))
 	}
 
-	// Формирую запрос через вызов HEAD
-	req, err := http.NewRequestWithContext(ctx, http.MethodHead, u.String(), nil)
-	if err != nil {
-		return false, err
+	maxRedirects := opts.MaxRedirects
+	if maxRedirects <= 0 {
+		maxRedirects = models.DefaultMaxRedirects
 	}
 
-	// Выполняю HTTP-запрос
-	resp, err := client.Do(req)
-	if err != nil {
-		return false, err
+	// с ожиданиями нужен GET с телом
+	var bodyLimit int64
+	if opts.Expect != nil {
+		bodyLimit = defaultBodyLimit
+		if opts.Expect.MaxBytes > 0 {
+			bodyLimit = opts.Expect.MaxBytes
+		}
 	}
-	defer resp.Body.Close()
 
-	// Если метод HEAD не поддерживается, используем GET
-	if resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented {
-		// само тело ответа не требуется, отбрасываем его
-		_, _ = io.Copy(io.Discard, resp.Body)
-		resp.Body.Close()
+	current := u
+	visited := map[string]bool{current.String(): true}
+	for {
+		if opts.Robots && !c.robotsAllowed(ctx, current) {
+			res.Status = models.StatusDisallowed
+			res.FinalURL = current.String()
+			res.Error = "disallowed by robots.txt"
+			return res, nil
+		}
+		if opts.Robots {
+			if err := c.robotsWait(ctx, current); err != nil {
+				return failNet(err)
+			}
+		}
 
-		req, err = http.NewRequest(http.MethodGet, u.String(), nil)
+		var timings models.Timings
+		resp, attempts, err := c.doWithRetry(ctx, current, &timings, bodyLimit)
+		res.Attempts += attempts
+		res.Timings = &timings
 		if err != nil {
-			return false, err
+			if current.Scheme == "https" {
+				res.Cert = c.certFromError(err, time.Now())
+			}
+			return failNet(err)
+		}
+		if cert := c.certFromResponse(resp.Response, time.Now()); cert != nil {
+			res.Cert = cert
+		}
+		res.Method = resp.Method
+		res.StatusCode = resp.StatusCode
+		res.FinalURL = current.String()
+
+		next, ok := redirectTarget(current, resp.Response)
+		if !ok {
+			res.Status = classifyResponse(resp.StatusCode, len(res.Redirects) > 0)
+			if opts.Expect != nil {
+				applyExpectations(&res, opts.Expect, resp)
+			}
+			if res.Status == models.StatusAvailable && c.cfg.SlowThreshold > 0 &&
+				time.Duration(timings.TotalMs)*time.Millisecond > c.cfg.SlowThreshold {
+				res.Status = models.StatusDegraded
+			}
+			// Если 400-ые и 500-ые коды, значит сайт недоступен
+			if len(res.AssertionFailures) > 0 {
+				res.Error = strings.Join(res.AssertionFailures, "; ")
+			} else if !res.Status.OK() {
+				res.Error = resp.Status
+			}
+			return res, nil
 		}
 
-		resp, err = client.Do(req)
-		if err != nil {
-			return false, err
+		res.Redirects = append(res.Redirects, models.RedirectHop{
+			URL:        current.String(),
+			StatusCode: resp.StatusCode,
+			Location:   next.String(),
+		})
+		if !sameSite(u, next) {
+			res.CrossDomain = true
+		}
+		if current.Scheme == "https" && next.Scheme == "http" {
+			res.HTTPSDowngrade = true
+		}
+
+		// Политика запрещает идти дальше - результатом остаётся ответ 3xx, ожидания проверяются на нём
+		if opts.RedirectPolicy == models.RedirectNone ||
+			(opts.RedirectPolicy == models.RedirectSameHost && !strings.EqualFold(next.Host, u.Host)) {
+			res.Status = models.StatusRedirected
+			if opts.Expect != nil {
+				applyExpectations(&res, opts.Expect, resp)
+				if len(res.AssertionFailures) > 0 {
+					res.Error = strings.Join(res.AssertionFailures, "; ")
+				}
+			}
+			return res, nil
 		}
-		defer resp.Body.Close()
-	}
 
-	// Ссылка доступна
-	if resp.StatusCode >= 200 && resp.StatusCode < 400 {
-		return true, nil
+		if visited[next.String()] {
+			res.RedirectLoop = true
+			res.Status = models.StatusRedirectError
+			return fail(fmt.Errorf("redirect loop at %s", next))
+		}
+		if len(res.Redirects) >= maxRedirects {
+			res.TooManyRedirects = true
+			res.Status = models.StatusRedirectError
+			return fail(fmt.Errorf("stopped after %d redirects", maxRedirects))
+		}
+
+		visited[next.String()] = true
+		current = next
 	}
+}
 
-	// Если 400-ые и 500-ые коды, значит сайт недоступен
-	return false, nil
+// response - ответ сервера. Тело к этому моменту уже закрыто; если его просили прочитать,
+// начало лежит в Body, а Truncated говорит, что тело было длиннее.
+type response struct {
+	*http.Response
+	Method    string
+	Body      []byte
+	Truncated bool
 }
 
-// CreatePDF - создает PDF файл с обработанными ссылками.
-func CreatePDF(data map[int]models.ResponseSentLinks) ([]byte, error) {
-	// Cоздаю pdf
-	pdf := gofpdf.New("P", "mm", "A4", "")
-	pdf.SetTitle("Links report", false)
-	pdf.AddPage()
-	pdf.SetFont("Arial", "", 12)
-
-	// для удобства собираем ключи мапы в слайс, далее их сортировка
-	reqKeys := make([]int, 0, len(data))
-	for k := range data {
-		reqKeys = append(reqKeys, k)
+// doRequest - HEAD-запрос, а если сервер его не поддерживает - GET. Тело ответа не нужно и закрывается.
+// С bodyLimit > 0 сразу идёт GET и читается не больше bodyLimit байт тела - для проверок содержимого.
+// В t записываются тайминги последнего отправленного запроса.
+func (c *LinkChecker) doRequest(ctx context.Context, u *url.URL, t *models.Timings, bodyLimit int64) (*response, error) {
+	if bodyLimit > 0 {
+		return c.send(ctx, http.MethodGet, u, t, bodyLimit)
 	}
-	slices.Sort(reqKeys)
-
-	// проходимся циклом по data и обрабатываем данные для формирования PDF
-	for _, key := range reqKeys {
-		resp := data[key]
-
-		// заголовок для конкретного блока
-		header := fmt.Sprintf("Request #%d", resp.Num)
-		pdf.SetFont("Arial", "B", 12)
-		pdf.Cell(0, 8, header)
-		pdf.Ln(10)
-
-		// сортировка ссылок для вывода по алфавиту
-		linkKeys := make([]string, 0, len(resp.Links))
-		for link := range resp.Links {
-			linkKeys = append(linkKeys, link)
-		}
-		slices.Sort(linkKeys)
 
-		// добавление самих ссылок со статусами.
-		pdf.SetFont("Arial", "", 11)
-		for _, link := range linkKeys {
-			status := resp.Links[link]
+	// Формирую запрос через вызов HEAD
+	resp, err := c.send(ctx, http.MethodHead, u, t, 0)
+	if err != nil {
+		return nil, err
+	}
 
-			line := fmt.Sprintf("%s - %s", link, status)
-			pdf.Cell(0, 6, line)
-			pdf.Ln(6)
-		}
+	// Если метод HEAD не поддерживается, используем GET
+	if resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented {
+		return c.send(ctx, http.MethodGet, u, t, 0)
+	}
+
+	return resp, nil
+}
+
+// send - один запрос с учётом лимитов хоста. Слот хоста держится, пока не дочитано тело.
+// Тайминги считаются с момента, когда слот получен: ожидание в очереди к хосту в них не входит.
+func (c *LinkChecker) send(ctx context.Context, method string, u *url.URL, t *models.Timings, bodyLimit int64) (*response, error) {
+	release, err := c.limiter.Acquire(ctx, u.Hostname())
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	traceCtx, tr := withTracer(ctx)
+	defer func() { *t = tr.done() }()
 
-		// Пустая строка между блоками запросов
-		pdf.Ln(4)
+	req, err := http.NewRequestWithContext(traceCtx, method, u.String(), nil)
+	if err != nil {
+		return nil, err
 	}
+	req.Header.Set("User-Agent", c.cfg.UserAgent)
 
-	// Буфер памяти, куда кладется готовый PDF
-	var buf bytes.Buffer
-	if err := pdf.Output(&buf); err != nil {
+	// Выполняю HTTP-запрос
+	resp, err := c.client.Do(req)
+	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
+
+	out := &response{Response: resp, Method: method}
+	if bodyLimit <= 0 {
+		// само тело ответа не требуется, отбрасываем его
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+		return out, nil
+	}
 
-	// Возврат байты PDF.
-	return buf.Bytes(), nil
+	// читаем на байт больше, чтобы понять, что тело не влезло
+	body, err := io.ReadAll(io.LimitReader(resp.Body, bodyLimit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > bodyLimit {
+		out.Truncated = true
+		body = body[:bodyLimit]
+	}
+	out.Body = body
+	return out, nil
 }
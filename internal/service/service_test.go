@@ -2,6 +2,9 @@ package service
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
 	"testing"
 
 	"github.com/NailUsmanov/linkchecker11_11_2025/internal/models"
@@ -9,45 +12,87 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+// testAllow - httptest-серверы слушают loopback, в тестах его разрешаем.
+var testAllow = Allowlist{Networks: []netip.Prefix{
+	netip.MustParsePrefix("127.0.0.0/8"),
+	netip.MustParsePrefix("::1/128"),
+}}
+
+// newTestChecker - проверяльщик с настройками по умолчанию и разрешённым loopback.
+func newTestChecker() *LinkChecker {
+	return NewLinkChecker(CheckerConfig{Allow: testAllow})
+}
+
 func TestCheckLink_Empty(t *testing.T) {
 	ctx := context.Background()
 
-	ok, err := CheckLink(ctx, "")
-	assert.False(t, ok)
+	res, err := newTestChecker().CheckLink(ctx, "", models.CheckOptions{})
 	require.Error(t, err)
+	assert.Equal(t, models.StatusInvalidURL, res.Status)
+	assert.NotEmpty(t, res.Error)
 }
 
 func TestCheckLink_InvalidURL(t *testing.T) {
 	ctx := context.Background()
 
-	ok, err := CheckLink(ctx, "://bad")
-	assert.False(t, ok)
+	res, err := newTestChecker().CheckLink(ctx, "://bad", models.CheckOptions{})
 	require.Error(t, err)
+	assert.Equal(t, models.StatusInvalidURL, res.Status)
+	assert.NotEmpty(t, res.Error)
 }
 
 func TestCheckLink_UnsupportedScheme(t *testing.T) {
 	ctx := context.Background()
 
-	ok, err := CheckLink(ctx, "ftp://example.com")
-	assert.False(t, ok)
-	require.Error(t, err)
+	res, err := newTestChecker().CheckLink(ctx, "gopher://example.com", models.CheckOptions{})
+	require.ErrorIs(t, err, ErrUnsupportedScheme)
+	assert.Equal(t, models.StatusInvalidURL, res.Status)
+	assert.NotEmpty(t, res.Error)
 }
 
-func TestCreatePDF(t *testing.T) {
-	data := map[int]models.ResponseSentLinks{
-		1: {
-			Num: 1,
-			Links: map[string]string{
-				"google.com": "available",
-				"ya.ru":      "not available",
-			},
-		},
-	}
+func TestCheckLink_Result(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	res, err := newTestChecker().CheckLink(context.Background(), srv.URL, models.CheckOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, srv.URL, res.URL)
+	assert.Equal(t, models.StatusAvailable, res.Status)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, http.MethodHead, res.Method)
+	// проверяется каноническая форма, у неё пустой путь стал "/"
+	assert.Equal(t, srv.URL+"/", res.Canonical)
+	assert.Equal(t, srv.URL+"/", res.FinalURL)
+	assert.False(t, res.CheckedAt.IsZero())
+}
+
+func TestCheckLink_HeadFallbackToGet(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	res, err := newTestChecker().CheckLink(context.Background(), srv.URL, models.CheckOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, http.MethodGet, res.Method)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+}
 
-	pdf, err := CreatePDF(data)
+func TestCheckLink_NotFound(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
 
+	res, err := newTestChecker().CheckLink(context.Background(), srv.URL, models.CheckOptions{})
 	require.NoError(t, err)
-	require.NotNil(t, pdf)
-	assert.Greater(t, len(pdf), 0)
 
+	assert.Equal(t, models.StatusClientError, res.Status)
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
 }
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/models"
+)
+
+const (
+	// maxSitemapBytes - предел размера одной карты сайта после распаковки, как в протоколе sitemaps.org.
+	maxSitemapBytes = 50 << 20
+	// maxSitemapLinks - сколько ссылок берётся из всех карт одного запроса.
+	maxSitemapLinks = 50000
+	// maxSitemapFiles - сколько карт скачивается за запрос, считая вложенные из индексов.
+	maxSitemapFiles = 100
+)
+
+// ErrSitemap - карту сайта не удалось получить или разобрать.
+var ErrSitemap = errors.New("sitemap")
+
+// sitemapDoc - urlset или sitemapindex: заполняется одно из полей.
+type sitemapDoc struct {
+	URLs     []sitemapLoc `xml:"url"`
+	Sitemaps []sitemapLoc `xml:"sitemap"`
+}
+
+type sitemapLoc struct {
+	Loc string `xml:"loc"`
+}
+
+// ExpandSitemaps - ссылки из <loc> всех карт сайта в порядке появления, без повторов.
+// Индексы карт раскрываются рекурсивно, сжатые gzip карты распаковываются.
+// Любая карта, которую не удалось получить, - ошибка ErrSitemap: проверять половину сайта молча не стоит.
+func (c *LinkChecker) ExpandSitemaps(ctx context.Context, sitemaps []string) ([]models.LinkSpec, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+
+	e := &sitemapExpander{c: c, visited: make(map[string]bool), seen: make(map[string]bool)}
+	for _, sm := range sitemaps {
+		if err := e.expand(ctx, sm); err != nil {
+			return nil, err
+		}
+	}
+	return e.links, nil
+}
+
+// ValidateSitemaps - проверяет адреса карт без скачивания. Сами карты раскрывает задача (см. JobQueue).
+func ValidateSitemaps(sitemaps []string) error {
+	for _, sm := range sitemaps {
+		canonical, err := NormalizeURL(sm, false)
+		if err != nil {
+			return fmt.Errorf("%w %s: %v", ErrSitemap, sm, err)
+		}
+		u, err := url.Parse(canonical)
+		if err != nil {
+			return fmt.Errorf("%w %s: %v", ErrSitemap, sm, err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return fmt.Errorf("%w %s: %v", ErrSitemap, sm, ErrUnsupportedScheme)
+		}
+	}
+	return nil
+}
+
+// sitemapExpander - состояние раскрытия карт одного запроса.
+type sitemapExpander struct {
+	c       *LinkChecker
+	visited map[string]bool
+	seen    map[string]bool
+	links   []models.LinkSpec
+}
+
+func (e *sitemapExpander) expand(ctx context.Context, sitemap string) error {
+	canonical, err := NormalizeURL(sitemap, false)
+	if err != nil {
+		return fmt.Errorf("%w %s: %v", ErrSitemap, sitemap, err)
+	}
+	if e.visited[canonical] {
+		return nil
+	}
+	if len(e.visited) >= maxSitemapFiles {
+		return fmt.Errorf("%w: more than %d sitemaps", ErrSitemap, maxSitemapFiles)
+	}
+	e.visited[canonical] = true
+
+	doc, err := e.c.fetchSitemap(ctx, canonical)
+	if err != nil {
+		return fmt.Errorf("%w %s: %v", ErrSitemap, sitemap, err)
+	}
+
+	for _, u := range doc.URLs {
+		loc := strings.TrimSpace(u.Loc)
+		if loc == "" || e.seen[loc] {
+			continue
+		}
+		if len(e.links) >= maxSitemapLinks {
+			return fmt.Errorf("%w: more than %d links", ErrSitemap, maxSitemapLinks)
+		}
+		e.seen[loc] = true
+		e.links = append(e.links, models.LinkSpec{URL: loc})
+	}
+	for _, sm := range doc.Sitemaps {
+		if loc := strings.TrimSpace(sm.Loc); loc != "" {
+			if err := e.expand(ctx, loc); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// fetchSitemap - скачивает и разбирает одну карту. Сжатие определяется по содержимому, а не по расширению:
+// сервер может отдать .xml.gz как с Content-Encoding, так и без.
+func (c *LinkChecker) fetchSitemap(ctx context.Context, sitemap string) (sitemapDoc, error) {
+	u, err := url.Parse(sitemap)
+	if err != nil {
+		return sitemapDoc{}, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return sitemapDoc{}, fmt.Errorf("%w: %s", ErrUnsupportedScheme, u.Scheme)
+	}
+
+	resp, err := c.fetchFollow(ctx, u, maxSitemapBytes)
+	if err != nil {
+		return sitemapDoc{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return sitemapDoc{}, errors.New(resp.Status)
+	}
+	if resp.Truncated {
+		return sitemapDoc{}, fmt.Errorf("larger than %d bytes", maxSitemapBytes)
+	}
+
+	var body io.Reader = bytes.NewReader(resp.Body)
+	if bytes.HasPrefix(resp.Body, []byte{0x1f, 0x8b}) {
+		zr, err := gzip.NewReader(body)
+		if err != nil {
+			return sitemapDoc{}, err
+		}
+		defer zr.Close()
+		body = &limitedReader{r: zr, n: maxSitemapBytes}
+	}
+
+	var doc sitemapDoc
+	if err := xml.NewDecoder(body).Decode(&doc); err != nil {
+		return sitemapDoc{}, fmt.Errorf("invalid xml: %w", err)
+	}
+	return doc, nil
+}
+
+// limitedReader - как io.LimitReader, но с ошибкой при превышении, чтобы не разобрать обрезанную карту как целую.
+type limitedReader struct {
+	r io.Reader
+	n int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.n <= 0 {
+		return 0, fmt.Errorf("larger than %d bytes unpacked", maxSitemapBytes)
+	}
+	if int64(len(p)) > l.n {
+		p = p[:l.n+1]
+	}
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	if l.n < 0 {
+		return n, fmt.Errorf("larger than %d bytes unpacked", maxSitemapBytes)
+	}
+	return n, err
+}
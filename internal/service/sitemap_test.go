@@ -0,0 +1,84 @@
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gzipped - сжатое содержимое для карты .xml.gz.
+func gzipped(t *testing.T, s string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write([]byte(s))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func TestExpandSitemaps(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sitemap_index.xml":
+			w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>` + srv.URL + `/pages.xml</loc></sitemap>
+  <sitemap><loc>` + srv.URL + `/posts.xml.gz</loc></sitemap>
+  <sitemap><loc>` + srv.URL + `/pages.xml</loc></sitemap>
+</sitemapindex>`))
+		case "/pages.xml":
+			w.Write([]byte(`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>https://example.com/</loc><lastmod>2025-11-01</lastmod></url>
+  <url><loc> https://example.com/about </loc></url>
+</urlset>`))
+		case "/posts.xml.gz":
+			w.Header().Set("Content-Type", "application/gzip")
+			w.Write(gzipped(t, `<urlset><url><loc>https://example.com/posts/1</loc></url><url><loc>https://example.com/about</loc></url></urlset>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	links, err := newTestChecker().ExpandSitemaps(context.Background(), []string{srv.URL + "/sitemap_index.xml"})
+	require.NoError(t, err)
+	assert.Equal(t, models.LinkSpecs("https://example.com/", "https://example.com/about", "https://example.com/posts/1"), links)
+
+	_, err = newTestChecker().ExpandSitemaps(context.Background(), []string{srv.URL + "/missing.xml"})
+	require.ErrorIs(t, err, ErrSitemap)
+	assert.Contains(t, err.Error(), "404")
+
+	_, err = newTestChecker().ExpandSitemaps(context.Background(), []string{"ftp://example.com/sitemap.xml"})
+	require.ErrorIs(t, err, ErrSitemap)
+}
+
+func TestExpandSitemaps_Redirect(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sitemap.xml":
+			http.Redirect(w, r, "/new/sitemap.xml", http.StatusMovedPermanently)
+		case "/new/sitemap.xml":
+			w.Write([]byte(`<urlset><url><loc>https://example.com/</loc></url></urlset>`))
+		case "/loop.xml":
+			http.Redirect(w, r, "/loop.xml", http.StatusFound)
+		}
+	}))
+	defer srv.Close()
+
+	links, err := newTestChecker().ExpandSitemaps(context.Background(), []string{srv.URL + "/sitemap.xml"})
+	require.NoError(t, err)
+	assert.Equal(t, models.LinkSpecs("https://example.com/"), links)
+
+	_, err = newTestChecker().ExpandSitemaps(context.Background(), []string{srv.URL + "/loop.xml"})
+	require.ErrorIs(t, err, ErrSitemap)
+	assert.Contains(t, err.Error(), "redirects")
+}
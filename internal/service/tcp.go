@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/textproto"
+	"net/url"
+	"time"
+
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/models"
+)
+
+// errNoPort - в tcp-ссылке не указан порт.
+var errNoPort = errors.New("tcp link needs host and port")
+
+// connStatus - статус ошибки подключения: ссылка без порта - некорректная, остальное - сетевые ошибки.
+func connStatus(err error) models.LinkStatus {
+	if errors.Is(err, errNoPort) {
+		return models.StatusInvalidURL
+	}
+	return classifyError(err)
+}
+
+// connect - TCP-подключение к хосту с учётом лимитов хоста и сетевой политики.
+// release нужно вызвать после закрытия соединения.
+func (c *LinkChecker) connect(ctx context.Context, u *url.URL, defaultPort string) (conn net.Conn, release func(), t models.Timings, err error) {
+	port := u.Port()
+	if port == "" {
+		port = defaultPort
+	}
+	if u.Hostname() == "" || port == "" {
+		return nil, nil, t, errNoPort
+	}
+
+	release, err = c.limiter.Acquire(ctx, u.Hostname())
+	if err != nil {
+		return nil, nil, t, err
+	}
+
+	start := time.Now()
+	conn, err = c.dial(ctx, "tcp", net.JoinHostPort(u.Hostname(), port))
+	t.ConnectMs = since(start)
+	t.TotalMs = t.ConnectMs
+	if err != nil {
+		release()
+		return nil, nil, t, err
+	}
+	return conn, release, t, nil
+}
+
+// checkTCP - ссылка tcp://host:port рабочая, если порт принимает соединения.
+func (c *LinkChecker) checkTCP(ctx context.Context, u *url.URL, _ models.CheckOptions) (models.CheckResult, error) {
+	conn, release, t, err := c.connect(ctx, u, "")
+	res := models.CheckResult{Timings: &t}
+	if err != nil {
+		res.Status = connStatus(err)
+		res.Error = err.Error()
+		return res, err
+	}
+	conn.Close()
+	release()
+
+	res.Status = models.StatusAvailable
+	return res, nil
+}
+
+// checkFTP - подключается к FTP-серверу и читает приветствие. Без логина: проверяется только, что сервер отвечает.
+// Код приветствия попадает в StatusCode, рабочим считается 2xx (обычно 220).
+func (c *LinkChecker) checkFTP(ctx context.Context, u *url.URL, _ models.CheckOptions) (models.CheckResult, error) {
+	conn, release, t, err := c.connect(ctx, u, "21")
+	res := models.CheckResult{Timings: &t}
+	if err != nil {
+		res.Status = connStatus(err)
+		res.Error = err.Error()
+		return res, err
+	}
+	defer release()
+	defer conn.Close()
+
+	deadline := time.Now().Add(c.cfg.RequestTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	_ = conn.SetDeadline(deadline)
+
+	start := time.Now()
+	tp := textproto.NewConn(conn)
+	code, msg, err := tp.ReadResponse(0)
+	t.TTFBMs = since(start)
+	t.TotalMs += t.TTFBMs
+	if err != nil {
+		res.Status = classifyError(err)
+		res.Error = err.Error()
+		return res, err
+	}
+	// вежливо прощаемся, ответ не важен
+	_ = tp.PrintfLine("QUIT")
+
+	res.StatusCode = code
+	if code/100 != 2 {
+		res.Status = models.StatusServerError
+		res.Error = fmt.Sprintf("ftp: %d %s", code, msg)
+		return res, nil
+	}
+	res.Status = models.StatusAvailable
+	return res, nil
+}
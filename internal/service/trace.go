@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/models"
+)
+
+// tracer - собирает тайминги одного запроса через httptrace.
+// Колбэки зовутся из разных горутин (например, параллельные подключения к IPv4 и IPv6), поэтому под мьютексом.
+type tracer struct {
+	mu        sync.Mutex
+	start     time.Time
+	dnsStart  time.Time
+	connStart time.Time
+	tlsStart  time.Time
+	t         models.Timings
+}
+
+// withTracer - контекст запроса с трассировкой.
+func withTracer(ctx context.Context) (context.Context, *tracer) {
+	tr := &tracer{start: time.Now()}
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			tr.mu.Lock()
+			tr.dnsStart = time.Now()
+			tr.mu.Unlock()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			tr.mu.Lock()
+			tr.t.DNSMs = since(tr.dnsStart)
+			tr.mu.Unlock()
+		},
+		ConnectStart: func(network, addr string) {
+			tr.mu.Lock()
+			if tr.connStart.IsZero() {
+				tr.connStart = time.Now()
+			}
+			tr.mu.Unlock()
+		},
+		ConnectDone: func(network, addr string, err error) {
+			tr.mu.Lock()
+			// учитываем первое удачное подключение
+			if err == nil && tr.t.ConnectMs == 0 {
+				tr.t.ConnectMs = since(tr.connStart)
+			}
+			tr.mu.Unlock()
+		},
+		TLSHandshakeStart: func() {
+			tr.mu.Lock()
+			tr.tlsStart = time.Now()
+			tr.mu.Unlock()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			tr.mu.Lock()
+			tr.t.TLSMs = since(tr.tlsStart)
+			tr.mu.Unlock()
+		},
+		GotFirstResponseByte: func() {
+			tr.mu.Lock()
+			tr.t.TTFBMs = since(tr.start)
+			tr.mu.Unlock()
+		},
+	}), tr
+}
+
+// done - итоговые тайминги, вызывается после того, как дочитано тело.
+func (tr *tracer) done() models.Timings {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	tr.t.TotalMs = since(tr.start)
+	return tr.t
+}
+
+// since - миллисекунды с момента t, ноль если отметки не было.
+func since(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return time.Since(t).Milliseconds()
+}
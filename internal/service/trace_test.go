@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func slowServer(delay time.Duration) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func TestCheckLink_Timings(t *testing.T) {
+	srv := slowServer(50 * time.Millisecond)
+	defer srv.Close()
+
+	res, err := newTestChecker().CheckLink(context.Background(), srv.URL, models.CheckOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, models.StatusAvailable, res.Status)
+	require.NotNil(t, res.Timings)
+	assert.GreaterOrEqual(t, res.Timings.TTFBMs, int64(50))
+	assert.GreaterOrEqual(t, res.Timings.TotalMs, res.Timings.TTFBMs)
+	// адрес - IP, резолвить нечего; TLS нет
+	assert.Zero(t, res.Timings.DNSMs)
+	assert.Zero(t, res.Timings.TLSMs)
+}
+
+func TestCheckLink_Degraded(t *testing.T) {
+	srv := slowServer(50 * time.Millisecond)
+	defer srv.Close()
+
+	checker := NewLinkChecker(CheckerConfig{Allow: testAllow, SlowThreshold: 20 * time.Millisecond})
+	res, err := checker.CheckLink(context.Background(), srv.URL, models.CheckOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, models.StatusDegraded, res.Status)
+	assert.True(t, res.Status.OK())
+	assert.Empty(t, res.Error)
+
+	checker = NewLinkChecker(CheckerConfig{Allow: testAllow, SlowThreshold: time.Second})
+	res, err = checker.CheckLink(context.Background(), srv.URL, models.CheckOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusAvailable, res.Status)
+}
+
+func TestCheckLink_TimingsTLS(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	// рукопожатие падает на проверке сертификата, но тайминги уже собраны
+	res, err := newTestChecker().CheckLink(context.Background(), srv.URL, models.CheckOptions{})
+	require.Error(t, err)
+	require.NotNil(t, res.Timings)
+	assert.GreaterOrEqual(t, res.Timings.TotalMs, res.Timings.TLSMs)
+}
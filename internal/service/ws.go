@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/models"
+)
+
+// websocketGUID - константа из RFC 6455 для проверки Sec-WebSocket-Accept.
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// checkWebSocket - проверяет, что ws:// или wss:// ссылка проходит рукопожатие WebSocket.
+// Соединение сразу закрывается, сообщения не отправляются. Редиректы не проходятся.
+func (c *LinkChecker) checkWebSocket(ctx context.Context, u *url.URL, _ models.CheckOptions) (res models.CheckResult, err error) {
+	res = models.CheckResult{Method: http.MethodGet}
+	fail := func(err error) (models.CheckResult, error) {
+		res.Status = classifyError(err)
+		res.Error = err.Error()
+		return res, err
+	}
+
+	target := *u
+	target.Scheme = "http"
+	if u.Scheme == "wss" {
+		target.Scheme = "https"
+	}
+
+	key, err := websocketKey()
+	if err != nil {
+		return fail(err)
+	}
+
+	release, err := c.limiter.Acquire(ctx, u.Hostname())
+	if err != nil {
+		return fail(err)
+	}
+	defer release()
+
+	traceCtx, tr := withTracer(ctx)
+	defer func() {
+		t := tr.done()
+		res.Timings = &t
+	}()
+
+	req, err := http.NewRequestWithContext(traceCtx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return fail(err)
+	}
+	req.Header.Set("User-Agent", c.cfg.UserAgent)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", key)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		if u.Scheme == "wss" {
+			res.Cert = c.certFromError(err, time.Now())
+		}
+		return fail(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	}
+
+	res.Cert = c.certFromResponse(resp, time.Now())
+	res.StatusCode = resp.StatusCode
+	res.FinalURL = u.String()
+
+	switch {
+	case resp.StatusCode >= 400:
+		res.Status = classifyResponse(resp.StatusCode, false)
+		res.Error = resp.Status
+	case resp.StatusCode != http.StatusSwitchingProtocols:
+		res.Status = models.StatusUnreachable
+		res.Error = fmt.Sprintf("websocket handshake failed: %s", resp.Status)
+	case resp.Header.Get("Sec-WebSocket-Accept") != websocketAccept(key):
+		res.Status = models.StatusUnreachable
+		res.Error = "websocket handshake failed: bad Sec-WebSocket-Accept"
+	default:
+		res.Status = models.StatusAvailable
+	}
+	return res, nil
+}
+
+// websocketKey - случайный Sec-WebSocket-Key.
+func websocketKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+// websocketAccept - какой Sec-WebSocket-Accept должен вернуть сервер на key.
+func websocketAccept(key string) string {
+	sum := sha1.Sum([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 
 	"github.com/NailUsmanov/linkchecker11_11_2025/internal/models"
@@ -13,46 +14,79 @@ import (
 
 // FileStorage хранит данные в памяти и периодически сбрасывает их в JSON-файл.
 // При старте сервера пытается прочитать данные из файла.
+// Незавершённые фоновые задачи лежат рядом, в отдельном файле (data.json -> data.jobs.json).
 type FileStorage struct {
-	mu   sync.RWMutex
-	path string
-	data map[int]models.ResponseSentLinks
+	mu       sync.RWMutex
+	path     string
+	jobsPath string
+	data     map[int]models.ResponseSentLinks
+	jobs     map[int]models.Job
+	lastID   int
 }
 
 // NewFileStorage создаёт файловое хранилище.
 // Если файл существует — читаем данные, если нет — начинаем с пустой мапы.
 func NewFileStorage(path string) (*FileStorage, error) {
 	fs := &FileStorage{
-		path: path,
-		data: make(map[int]models.ResponseSentLinks),
+		path:     path,
+		jobsPath: jobsPathFor(path),
+		data:     make(map[int]models.ResponseSentLinks),
+		jobs:     make(map[int]models.Job),
 	}
 
+	if err := readJSONFile(fs.path, &fs.data); err != nil {
+		return nil, err
+	}
+	if err := readJSONFile(fs.jobsPath, &fs.jobs); err != nil {
+		return nil, err
+	}
+
+	// продолжаем нумерацию с максимального сохранённого номера,
+	// учитывая и номера ещё не завершённых задач
+	for k := range fs.data {
+		if k > fs.lastID {
+			fs.lastID = k
+		}
+	}
+	for k := range fs.jobs {
+		if k > fs.lastID {
+			fs.lastID = k
+		}
+	}
+
+	return fs, nil
+}
+
+// jobsPathFor - путь к файлу задач рядом с основным файлом.
+func jobsPathFor(path string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + ".jobs" + ext
+}
+
+// readJSONFile читает JSON-файл в v. Отсутствующий или пустой файл — не ошибка.
+func readJSONFile(path string, v any) error {
 	info, err := os.Stat(path)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
 			// файла нет — ок, стартуем с пустой мапы
-			return fs, nil
+			return nil
 		}
-		return nil, err
+		return err
 	}
 
 	if info.IsDir() {
-		return nil, errors.New("file storage path is a directory")
+		return errors.New("file storage path is a directory")
 	}
 
 	b, err := os.ReadFile(path)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	if len(b) == 0 {
-		return fs, nil
-	}
-
-	if err := json.Unmarshal(b, &fs.data); err != nil {
-		return nil, err
+		return nil
 	}
 
-	return fs, nil
+	return json.Unmarshal(b, v)
 }
 
 func (f *FileStorage) Save(ctx context.Context, resp models.ResponseSentLinks) error {
@@ -60,7 +94,10 @@ func (f *FileStorage) Save(ctx context.Context, resp models.ResponseSentLinks) e
 	defer f.mu.Unlock()
 
 	f.data[resp.Num] = resp
-	return f.flush()
+	if resp.Num > f.lastID {
+		f.lastID = resp.Num
+	}
+	return writeJSONFile(f.path, f.data)
 }
 
 func (f *FileStorage) Get(ctx context.Context, nums []int) (map[int]models.ResponseSentLinks, error) {
@@ -76,14 +113,60 @@ func (f *FileStorage) Get(ctx context.Context, nums []int) (map[int]models.Respo
 	return res, nil
 }
 
-// flush сбрасывает всю мапу в JSON-файл через временный файл.
-func (f *FileStorage) flush() error {
-	b, err := json.MarshalIndent(f.data, "", "  ")
+// NextID выдает следующий номер запроса.
+// После перезапуска счётчик продолжается с максимального номера из файла.
+func (f *FileStorage) NextID(ctx context.Context) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.lastID++
+	return f.lastID, nil
+}
+
+// SaveJob сохраняет состояние фоновой задачи в файл задач.
+func (f *FileStorage) SaveJob(ctx context.Context, job models.Job) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.jobs[job.Num] = job
+	if job.Num > f.lastID {
+		f.lastID = job.Num
+	}
+	return writeJSONFile(f.jobsPath, f.jobs)
+}
+
+// DeleteJob убирает завершённую задачу из файла задач.
+func (f *FileStorage) DeleteJob(ctx context.Context, num int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.jobs[num]; !ok {
+		return nil
+	}
+	delete(f.jobs, num)
+	return writeJSONFile(f.jobsPath, f.jobs)
+}
+
+// Jobs возвращает все сохранённые задачи.
+func (f *FileStorage) Jobs(ctx context.Context) ([]models.Job, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	res := make([]models.Job, 0, len(f.jobs))
+	for _, j := range f.jobs {
+		res = append(res, j)
+	}
+	return res, nil
+}
+
+// writeJSONFile сбрасывает v в JSON-файл через временный файл.
+func writeJSONFile(path string, v any) error {
+	b, err := json.MarshalIndent(v, "", "  ")
 	if err != nil {
 		return err
 	}
 
-	dir := filepath.Dir(f.path)
+	dir := filepath.Dir(path)
 	tmpFile, err := os.CreateTemp(dir, "linkchecker-*.tmp")
 	if err != nil {
 		return err
@@ -101,7 +184,7 @@ func (f *FileStorage) flush() error {
 		return closeErr
 	}
 
-	if err := os.Rename(tmpPath, f.path); err != nil {
+	if err := os.Rename(tmpPath, path); err != nil {
 		_ = os.Remove(tmpPath)
 		return err
 	}
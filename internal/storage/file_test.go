@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NailUsmanov/linkchecker11_11_2025/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStorage_SaveAndReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.json")
+	ctx := context.Background()
+
+	s, err := NewFileStorage(path)
+	require.NoError(t, err)
+
+	num, err := s.NextID(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, num)
+
+	resp := models.ResponseSentLinks{
+		Num: num,
+		Links: []models.CheckResult{
+			{URL: "google.com", Status: models.StatusAvailable, StatusCode: 200},
+		},
+	}
+	require.NoError(t, s.Save(ctx, resp))
+
+	// "перезапуск" — новое хранилище поверх того же файла
+	reloaded, err := NewFileStorage(path)
+	require.NoError(t, err)
+
+	out, err := reloaded.Get(ctx, []int{1})
+	require.NoError(t, err)
+	assert.Equal(t, resp, out[1])
+
+	// нумерация продолжается, а не начинается заново
+	next, err := reloaded.NextID(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, next)
+}
+
+func TestFileStorage_NextIDResumesFromMaxKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.json")
+	err := os.WriteFile(path, []byte(`{"3":{"links":{},"links_num":3},"7":{"links":{},"links_num":7}}`), 0o644)
+	require.NoError(t, err)
+
+	s, err := NewFileStorage(path)
+	require.NoError(t, err)
+
+	next, err := s.NextID(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 8, next)
+}
+
+func TestFileStorage_LoadsLegacyFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.json")
+	err := os.WriteFile(path, []byte(`{"1":{"links_num":1,"links":{"google.com":"available","ya.ru":"not available"}}}`), 0o644)
+	require.NoError(t, err)
+
+	s, err := NewFileStorage(path)
+	require.NoError(t, err)
+
+	out, err := s.Get(context.Background(), []int{1})
+	require.NoError(t, err)
+	require.Contains(t, out, 1)
+	res, ok := out[1].Find("google.com")
+	require.True(t, ok)
+	assert.Equal(t, models.StatusAvailable, res.Status)
+	res, ok = out[1].Find("ya.ru")
+	require.True(t, ok)
+	assert.Equal(t, models.StatusUnreachable, res.Status)
+}
+
+func TestFileStorage_PathIsDirectory(t *testing.T) {
+	_, err := NewFileStorage(t.TempDir())
+	require.Error(t, err)
+}
+
+func TestFileStorage_JobsSurviveRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.json")
+	ctx := context.Background()
+
+	s, err := NewFileStorage(path)
+	require.NoError(t, err)
+
+	num, err := s.NextID(ctx)
+	require.NoError(t, err)
+	job := models.Job{Num: num, State: models.JobRunning, Links: models.LinkSpecs("ya.ru"), Total: 1}
+	require.NoError(t, s.SaveJob(ctx, job))
+
+	// файл задач лежит рядом с основным
+	_, err = os.Stat(filepath.Join(filepath.Dir(path), "data.jobs.json"))
+	require.NoError(t, err)
+
+	reloaded, err := NewFileStorage(path)
+	require.NoError(t, err)
+
+	jobs, err := reloaded.Jobs(ctx)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, job, jobs[0])
+
+	// номер незавершённой задачи не выдаётся повторно
+	next, err := reloaded.NextID(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, num+1, next)
+
+	require.NoError(t, reloaded.DeleteJob(ctx, num))
+	jobs, err = reloaded.Jobs(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, jobs)
+}
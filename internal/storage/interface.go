@@ -10,4 +10,11 @@ import (
 type Storage interface {
 	Save(ctx context.Context, links models.ResponseSentLinks) error
 	Get(ctx context.Context, num []int) (map[int]models.ResponseSentLinks, error)
+	// NextID выдает номер для нового запроса. Номера не повторяются даже после перезапуска.
+	NextID(ctx context.Context) (int, error)
+
+	// SaveJob, DeleteJob и Jobs хранят незавершённые фоновые задачи, чтобы они пережили перезапуск.
+	SaveJob(ctx context.Context, job models.Job) error
+	DeleteJob(ctx context.Context, num int) error
+	Jobs(ctx context.Context) ([]models.Job, error)
 }
@@ -9,14 +9,17 @@ import (
 
 // MemoryStorage сущность для хранения ссылок и их статусов. Так как мапа непотокобезопасна, ставлю sync.RWmutex.
 type MemoryStorage struct {
-	mu   sync.RWMutex
-	data map[int]models.ResponseSentLinks
+	mu     sync.RWMutex
+	data   map[int]models.ResponseSentLinks
+	jobs   map[int]models.Job
+	lastID int
 }
 
 // NewMemoryStorage - создает новую структуру MemoryStorage.
 func NewMemoryStorage() *MemoryStorage {
 	return &MemoryStorage{
 		data: make(map[int]models.ResponseSentLinks),
+		jobs: make(map[int]models.Job),
 	}
 }
 
@@ -26,9 +29,21 @@ func (m *MemoryStorage) Save(ctx context.Context, resp models.ResponseSentLinks)
 	defer m.mu.Unlock()
 
 	m.data[resp.Num] = resp
+	if resp.Num > m.lastID {
+		m.lastID = resp.Num
+	}
 	return nil
 }
 
+// NextID - выдает следующий номер запроса.
+func (m *MemoryStorage) NextID(ctx context.Context) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.lastID++
+	return m.lastID, nil
+}
+
 // Get - достает данные в пдф файл и выдает пользователю провернные ссылки по номерам запроса.
 func (m *MemoryStorage) Get(ctx context.Context, nums []int) (map[int]models.ResponseSentLinks, error) {
 	m.mu.RLock()
@@ -42,3 +57,36 @@ func (m *MemoryStorage) Get(ctx context.Context, nums []int) (map[int]models.Res
 	}
 	return res, nil
 }
+
+// SaveJob - сохраняет состояние фоновой задачи.
+func (m *MemoryStorage) SaveJob(ctx context.Context, job models.Job) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.jobs[job.Num] = job
+	if job.Num > m.lastID {
+		m.lastID = job.Num
+	}
+	return nil
+}
+
+// DeleteJob - удаляет завершённую задачу.
+func (m *MemoryStorage) DeleteJob(ctx context.Context, num int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.jobs, num)
+	return nil
+}
+
+// Jobs - возвращает все сохранённые задачи.
+func (m *MemoryStorage) Jobs(ctx context.Context) ([]models.Job, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	res := make([]models.Job, 0, len(m.jobs))
+	for _, j := range m.jobs {
+		res = append(res, j)
+	}
+	return res, nil
+}
@@ -21,9 +21,9 @@ func TestMemoryStorage_SaveAndGet(t *testing.T) {
 
 	resp := models.ResponseSentLinks{
 		Num: 1,
-		Links: map[string]string{
-			"google.com": "available",
-			"ya.ru":      "available",
+		Links: []models.CheckResult{
+			{URL: "google.com", Status: models.StatusAvailable, StatusCode: 200},
+			{URL: "ya.ru", Status: models.StatusAvailable, StatusCode: 200},
 		},
 	}
 
@@ -48,8 +48,8 @@ func TestMemoryStorage_GetNotFound(t *testing.T) {
 
 	err := s.Save(ctx, models.ResponseSentLinks{
 		Num: 1,
-		Links: map[string]string{
-			"google.com": "available",
+		Links: []models.CheckResult{
+			{URL: "google.com", Status: models.StatusAvailable, StatusCode: 200},
 		},
 	})
 	require.NoError(t, err)
@@ -67,3 +67,16 @@ func TestMemoryStorage_GetEmptyNums(t *testing.T) {
 	require.NoError(t, err)
 	assert.Len(t, out, 0)
 }
+
+func TestMemoryStorage_NextID(t *testing.T) {
+	s := NewMemoryStorage()
+	ctx := context.Background()
+
+	first, err := s.NextID(ctx)
+	require.NoError(t, err)
+	second, err := s.NextID(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, first)
+	assert.Equal(t, 2, second)
+}